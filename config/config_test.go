@@ -0,0 +1,32 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidarPagos(t *testing.T) {
+	porDefecto := PorDefecto()
+	if err := porDefecto.Validar(); err != nil {
+		t.Fatalf("la configuración por defecto no es válida: %s", err)
+	}
+
+	otro := PorDefecto()
+	otro.Pagos.Proveedor = "stripe"
+	if err := otro.Validar(); err == nil || !strings.Contains(err.Error(), "payments.provider") {
+		t.Errorf("proveedor desconocido: Validar = %v", err)
+	}
+
+	// Con secretos propios, lo único que impide producción es la pasarela
+	produccion := PorDefecto()
+	produccion.Entorno = EntornoProduccion
+	produccion.JWT.Secreto = "secreto-jwt-propio"
+	produccion.Pagos.SecretoWebhook = "secreto-webhook-propio"
+	err := produccion.Validar()
+	if err == nil || !strings.Contains(err.Error(), "no hay proveedor de pagos real disponible") {
+		t.Fatalf("producción: Validar = %v", err)
+	}
+	if strings.Count(err.Error(), "\n") > 0 {
+		t.Errorf("producción debería fallar por un solo motivo: %s", err)
+	}
+}
@@ -0,0 +1,99 @@
+package courses
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+)
+
+// Valores por defecto del caché de cursos.
+const (
+	TamanoCachePorDefecto = 1000
+	TTLPorDefecto         = 5 * time.Minute
+	TTLNegativoPorDefecto = 30 * time.Second
+)
+
+// CachedCatalog guarda en memoria las respuestas de otro catálogo. Los cursos
+// inexistentes también se recuerdan (con un TTL más corto) para no consultar
+// el servicio en cada intento; los errores de disponibilidad nunca se guardan.
+type CachedCatalog struct {
+	origen    Catalog
+	cursos    *expirable.LRU[string, Course]
+	noExisten *expirable.LRU[string, struct{}]
+}
+
+// NewCachedCatalog envuelve origen con un LRU de hasta tamano cursos. ttl
+// aplica a los cursos encontrados y ttlNegativo a los inexistentes.
+func NewCachedCatalog(origen Catalog, tamano int, ttl, ttlNegativo time.Duration) *CachedCatalog {
+	return &CachedCatalog{
+		origen:    origen,
+		cursos:    expirable.NewLRU[string, Course](tamano, nil, ttl),
+		noExisten: expirable.NewLRU[string, struct{}](tamano, nil, ttlNegativo),
+	}
+}
+
+func (c *CachedCatalog) GetCourse(ctx context.Context, courseID string) (*Course, error) {
+	if curso, ok := c.cursos.Get(courseID); ok {
+		return &curso, nil
+	}
+	if _, ok := c.noExisten.Get(courseID); ok {
+		return nil, fmt.Errorf("%w: %s", ErrCursoNoEncontrado, courseID)
+	}
+
+	curso, err := c.origen.GetCourse(ctx, courseID)
+	switch {
+	case errors.Is(err, ErrCursoNoEncontrado):
+		c.noExisten.Add(courseID, struct{}{})
+		return nil, err
+	case err != nil:
+		return nil, err
+	}
+
+	c.cursos.Add(courseID, *curso)
+	return curso, nil
+}
+
+// GetCourses resuelve desde el caché lo que pueda y pide el resto al
+// catálogo de origen en una sola consulta.
+func (c *CachedCatalog) GetCourses(ctx context.Context, courseIDs []string) (map[string]*Course, error) {
+	cursos := make(map[string]*Course, len(courseIDs))
+	var faltantes []string
+	for _, courseID := range courseIDs {
+		if curso, ok := c.cursos.Get(courseID); ok {
+			cursos[courseID] = &curso
+			continue
+		}
+		if _, ok := c.noExisten.Get(courseID); ok {
+			continue
+		}
+		faltantes = append(faltantes, courseID)
+	}
+	if len(faltantes) == 0 {
+		return cursos, nil
+	}
+
+	encontrados, err := c.origen.GetCourses(ctx, faltantes)
+	if err != nil {
+		return nil, err
+	}
+	for _, courseID := range faltantes {
+		curso, ok := encontrados[courseID]
+		if !ok {
+			c.noExisten.Add(courseID, struct{}{})
+			continue
+		}
+		c.cursos.Add(courseID, *curso)
+		cursos[courseID] = curso
+	}
+	return cursos, nil
+}
+
+// Invalidar descarta lo guardado para un curso, encontrado o no. Se llama
+// cuando el servicio de cursos avisa que el curso cambió.
+func (c *CachedCatalog) Invalidar(courseID string) {
+	c.cursos.Remove(courseID)
+	c.noExisten.Remove(courseID)
+}
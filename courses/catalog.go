@@ -0,0 +1,38 @@
+package courses
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrCursoNoEncontrado indica que el servicio de cursos respondió y el curso no existe.
+	ErrCursoNoEncontrado = errors.New("curso no encontrado")
+	// ErrServicioNoDisponible indica que no se pudo obtener una respuesta
+	// válida del servicio de cursos; no dice nada sobre la existencia del curso.
+	ErrServicioNoDisponible = errors.New("servicio de cursos no disponible")
+)
+
+// Course son los datos de un curso que este servicio necesita del catálogo.
+type Course struct {
+	ID        string
+	Title     string
+	Price     float64
+	Thumbnail string
+	// Instructor es el campo instructor del servicio de cursos. Por contrato
+	// con ese servicio contiene el userID del instructor en este servicio,
+	// no su nombre: es lo que autoriza a responder las reseñas del curso.
+	Instructor string
+}
+
+// Catalog abstrae al servicio de cursos para que los resolvers no dependan
+// del transporte ni de la dirección del servicio.
+type Catalog interface {
+	// GetCourse devuelve el curso pedido. Los errores envuelven
+	// ErrCursoNoEncontrado o ErrServicioNoDisponible.
+	GetCourse(ctx context.Context, courseID string) (*Course, error)
+	// GetCourses busca varios cursos en una sola consulta. Los cursos
+	// inexistentes no aparecen en el mapa; un error significa que no se pudo
+	// consultar el servicio.
+	GetCourses(ctx context.Context, courseIDs []string) (map[string]*Course, error)
+}
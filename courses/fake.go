@@ -0,0 +1,75 @@
+package courses
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// FakeCatalog es un catálogo en memoria para usar sin el servicio de cursos.
+type FakeCatalog struct {
+	mu           sync.RWMutex
+	cursos       map[string]Course
+	noDisponible bool
+}
+
+// NewFakeCatalog crea el catálogo con los cursos indicados.
+func NewFakeCatalog(cursos ...Course) *FakeCatalog {
+	c := &FakeCatalog{cursos: make(map[string]Course)}
+	for _, curso := range cursos {
+		c.cursos[curso.ID] = curso
+	}
+	return c
+}
+
+// Agregar registra o reemplaza un curso.
+func (c *FakeCatalog) Agregar(curso Course) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cursos[curso.ID] = curso
+}
+
+// Quitar elimina un curso del catálogo.
+func (c *FakeCatalog) Quitar(courseID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.cursos, courseID)
+}
+
+// SimularCaida hace que las consultas fallen con ErrServicioNoDisponible
+// mientras caido sea true.
+func (c *FakeCatalog) SimularCaida(caido bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.noDisponible = caido
+}
+
+func (c *FakeCatalog) GetCourse(ctx context.Context, courseID string) (*Course, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.noDisponible {
+		return nil, ErrServicioNoDisponible
+	}
+	curso, ok := c.cursos[courseID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCursoNoEncontrado, courseID)
+	}
+	return &curso, nil
+}
+
+func (c *FakeCatalog) GetCourses(ctx context.Context, courseIDs []string) (map[string]*Course, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.noDisponible {
+		return nil, ErrServicioNoDisponible
+	}
+	cursos := make(map[string]*Course, len(courseIDs))
+	for _, courseID := range courseIDs {
+		if curso, ok := c.cursos[courseID]; ok {
+			cursos[courseID] = &curso
+		}
+	}
+	return cursos, nil
+}
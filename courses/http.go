@@ -0,0 +1,145 @@
+package courses
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// EndpointPorDefecto es la dirección del servicio de cursos dentro de la red de Docker.
+const EndpointPorDefecto = "http://proyectoingesocursos:8081/graphql"
+
+// camposCurso son los campos que se piden de cada curso.
+const camposCurso = `{ courseID title price thumbnail instructor }`
+
+// HTTPCatalog consulta el servicio de cursos por GraphQL sobre HTTP.
+type HTTPCatalog struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewHTTPCatalog crea el cliente para endpoint. Si client es nil se usa uno
+// con un timeout de 5 segundos, compartido entre todas las consultas.
+func NewHTTPCatalog(endpoint string, client *http.Client) *HTTPCatalog {
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+	return &HTTPCatalog{endpoint: endpoint, client: client}
+}
+
+type peticionGraphQL struct {
+	Query     string            `json:"query"`
+	Variables map[string]string `json:"variables"`
+}
+
+type cursoGraphQL struct {
+	CourseID   string  `json:"courseID"`
+	Title      string  `json:"title"`
+	Price      float64 `json:"price"`
+	Thumbnail  string  `json:"thumbnail"`
+	Instructor string  `json:"instructor"`
+}
+
+type respuestaCursos struct {
+	// Cada alias (c0, c1, ...) es un cursoByID; nulo si el curso no existe
+	// o si su resolver falló, en cuyo caso Errors lo explica.
+	Data   map[string]*cursoGraphQL `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (c *HTTPCatalog) GetCourse(ctx context.Context, courseID string) (*Course, error) {
+	cursos, err := c.GetCourses(ctx, []string{courseID})
+	if err != nil {
+		return nil, err
+	}
+	curso, ok := cursos[courseID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCursoNoEncontrado, courseID)
+	}
+	return curso, nil
+}
+
+// GetCourses pide todos los cursos en una sola consulta, con un cursoByID
+// con alias por curso. Los IDs viajan siempre como variables.
+func (c *HTTPCatalog) GetCourses(ctx context.Context, courseIDs []string) (map[string]*Course, error) {
+	cursos := make(map[string]*Course, len(courseIDs))
+	if len(courseIDs) == 0 {
+		return cursos, nil
+	}
+
+	var parametros, campos strings.Builder
+	variables := make(map[string]string, len(courseIDs))
+	for i, courseID := range courseIDs {
+		if i > 0 {
+			parametros.WriteString(", ")
+		}
+		fmt.Fprintf(&parametros, "$id%d: String!", i)
+		fmt.Fprintf(&campos, " c%d: cursoByID(courseID: $id%d) %s", i, i, camposCurso)
+		variables[fmt.Sprintf("id%d", i)] = courseID
+	}
+
+	payload, err := json.Marshal(peticionGraphQL{
+		Query:     fmt.Sprintf("query(%s) {%s }", parametros.String(), campos.String()),
+		Variables: variables,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrServicioNoDisponible, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrServicioNoDisponible, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: código de respuesta %d", ErrServicioNoDisponible, resp.StatusCode)
+	}
+
+	var resultado respuestaCursos
+	if err := json.NewDecoder(resp.Body).Decode(&resultado); err != nil {
+		return nil, fmt.Errorf("%w: respuesta inválida: %v", ErrServicioNoDisponible, err)
+	}
+
+	// Sin "data" la consulta ni siquiera se ejecutó (por ejemplo, un error de
+	// validación)
+	if resultado.Data == nil {
+		mensaje := "respuesta sin datos"
+		if len(resultado.Errors) > 0 {
+			mensaje = resultado.Errors[0].Message
+		}
+		return nil, fmt.Errorf("%w: %s", ErrServicioNoDisponible, mensaje)
+	}
+
+	for i, courseID := range courseIDs {
+		curso := resultado.Data[fmt.Sprintf("c%d", i)]
+		if curso == nil {
+			// Un cursoByID nulo solo significa que el curso no existe si la
+			// respuesta no trae errores; con errores, el resolver falló
+			if len(resultado.Errors) > 0 {
+				return nil, fmt.Errorf("%w: %s", ErrServicioNoDisponible, resultado.Errors[0].Message)
+			}
+			continue
+		}
+		cursos[courseID] = &Course{
+			ID:         curso.CourseID,
+			Title:      curso.Title,
+			Price:      curso.Price,
+			Thumbnail:  curso.Thumbnail,
+			Instructor: curso.Instructor,
+		}
+	}
+	return cursos, nil
+}
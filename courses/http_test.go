@@ -0,0 +1,40 @@
+package courses_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ProyectoIngeso/courses"
+)
+
+// El campo instructor del servicio de cursos es el userID del instructor;
+// ResponderResena lo compara con el usuario autenticado.
+func TestHTTPCatalogLeeElInstructorComoUserID(t *testing.T) {
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var peticion struct {
+			Query     string            `json:"query"`
+			Variables map[string]string `json:"variables"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&peticion); err != nil {
+			t.Errorf("petición inválida: %s", err)
+		}
+		if !strings.Contains(peticion.Query, "instructor") || peticion.Variables["id0"] != "c1" {
+			t.Errorf("consulta = %q con %v", peticion.Query, peticion.Variables)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"c0": {"courseID": "c1", "title": "Go", "price": 10, "instructor": "u-instructor"}}}`))
+	}))
+	defer servidor.Close()
+
+	curso, err := courses.NewHTTPCatalog(servidor.URL, nil).GetCourse(context.Background(), "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if curso.Instructor != "u-instructor" || curso.ID != "c1" || curso.Price != 10 {
+		t.Errorf("curso = %+v", curso)
+	}
+}
@@ -0,0 +1,44 @@
+// Package database abre la conexión de GORM con el motor elegido en la
+// configuración.
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"ProyectoIngeso/config"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Abrir conecta con la base de datos descrita en cfg.
+func Abrir(cfg config.ConfigBD) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch cfg.Driver {
+	case config.DriverSQLite:
+		// SQLite no aplica las claves foráneas salvo que se pida en cada
+		// conexión. Con BEGIN IMMEDIATE una transacción toma el bloqueo de
+		// escritura al empezar, en lugar de fallar al intentar escribir.
+		dialector = sqlite.Open(conParametro(conParametro(cfg.Ruta, "_foreign_keys=on"), "_txlock=immediate"))
+	case config.DriverPostgres:
+		dialector = postgres.Open(cfg.DSN)
+	default:
+		return nil, fmt.Errorf("driver de base de datos no soportado: %q", cfg.Driver)
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo conectar a la base de datos (%s): %w", cfg.Driver, err)
+	}
+	return db, nil
+}
+
+// conParametro agrega un parámetro a la cadena de conexión de SQLite.
+func conParametro(ruta string, parametro string) string {
+	if strings.Contains(ruta, "?") {
+		return ruta + "&" + parametro
+	}
+	return ruta + "?" + parametro
+}
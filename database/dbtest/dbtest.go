@@ -0,0 +1,105 @@
+// Package dbtest abre bases de datos descartables para las pruebas, una por
+// cada motor soportado. SQLite se prueba siempre con un archivo temporal;
+// PostgreSQL solo si DATABASE_DSN apunta a un servidor, por ejemplo el de
+// docker-compose.test.yml:
+//
+//	docker compose -f docker-compose.test.yml up -d
+//	DATABASE_DSN="host=localhost port=5433 user=usuarios password=usuarios dbname=usuarios_test sslmode=disable" go test ./...
+//
+// Cada prueba de PostgreSQL trabaja en un schema propio que se borra al
+// terminar, así que las pruebas no dependen del contenido previo.
+package dbtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ProyectoIngeso/config"
+	"ProyectoIngeso/database"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// VariableDSN es la variable de entorno con la conexión a PostgreSQL.
+const VariableDSN = "DATABASE_DSN"
+
+// Motores son los drivers contra los que corren las pruebas.
+var Motores = []string{config.DriverSQLite, config.DriverPostgres}
+
+// ParaCadaMotor corre prueba como subprueba con una base vacía de cada
+// motor. La de PostgreSQL se omite si no hay DATABASE_DSN.
+func ParaCadaMotor(t *testing.T, prueba func(t *testing.T, db *gorm.DB)) {
+	t.Helper()
+	for _, motor := range Motores {
+		t.Run(motor, func(t *testing.T) {
+			prueba(t, Abrir(t, motor))
+		})
+	}
+}
+
+// Abrir devuelve una base vacía del motor indicado, que se descarta al
+// terminar la prueba.
+func Abrir(t testing.TB, motor string) *gorm.DB {
+	t.Helper()
+	switch motor {
+	case config.DriverSQLite:
+		return abrir(t, config.ConfigBD{Driver: motor, Ruta: filepath.Join(t.TempDir(), "pruebas.db")})
+	case config.DriverPostgres:
+		return abrirPostgres(t)
+	}
+	t.Fatalf("motor desconocido: %s", motor)
+	return nil
+}
+
+func abrirPostgres(t testing.TB) *gorm.DB {
+	dsn := os.Getenv(VariableDSN)
+	if dsn == "" {
+		t.Skipf("%s no está definida; se omite PostgreSQL", VariableDSN)
+	}
+
+	admin := abrir(t, config.ConfigBD{Driver: config.DriverPostgres, DSN: dsn})
+	schema := "prueba_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if err := admin.Exec(fmt.Sprintf(`CREATE SCHEMA "%s"`, schema)).Error; err != nil {
+		t.Fatalf("no se pudo crear el schema de prueba: %s", err)
+	}
+	t.Cleanup(func() {
+		if err := admin.Exec(fmt.Sprintf(`DROP SCHEMA "%s" CASCADE`, schema)).Error; err != nil {
+			t.Errorf("no se pudo borrar el schema de prueba: %s", err)
+		}
+	})
+
+	return abrir(t, config.ConfigBD{Driver: config.DriverPostgres, DSN: conSearchPath(dsn, schema)})
+}
+
+func abrir(t testing.TB, cfg config.ConfigBD) *gorm.DB {
+	db, err := database.Abrir(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Logger = logger.Default.LogMode(logger.Silent)
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// conSearchPath agrega el schema a la cadena de conexión, sea una URL o
+// pares clave=valor.
+func conSearchPath(dsn string, schema string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		separador := "?"
+		if strings.Contains(dsn, "?") {
+			separador = "&"
+		}
+		return dsn + separador + "search_path=" + schema
+	}
+	return dsn + " search_path=" + schema
+}
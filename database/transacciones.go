@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+)
+
+// Reintentos de una unidad de trabajo ante conflictos de concurrencia.
+const (
+	maxIntentosTransaccion = 5
+	esperaBaseReintento    = 10 * time.Millisecond
+)
+
+// EnTransaccion ejecuta fn como una unidad de trabajo: las lecturas que
+// validan la operación y las escrituras que la aplican ven el mismo estado.
+// Si la base reporta un conflicto (SQLite ocupada, fallo de serialización
+// en PostgreSQL) la transacción completa se reintenta con espera
+// exponencial, así que fn no debe tener efectos fuera de tx; lo que se
+// publique o notifique por otros medios va después de que EnTransaccion
+// termine sin error.
+func EnTransaccion(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	db = db.WithContext(ctx)
+	opciones := OpcionesTransaccion(db)
+
+	for intento := 1; ; intento++ {
+		err := db.Transaction(fn, opciones)
+		if err == nil || !EsConflicto(err) || intento == maxIntentosTransaccion {
+			return err
+		}
+
+		espera := esperaBaseReintento << (intento - 1)
+		espera += rand.N(espera) // Evita que los reintentos vuelvan a chocar
+		select {
+		case <-time.After(espera):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// OpcionesTransaccion devuelve el aislamiento a pedir al iniciar una unidad
+// de trabajo. PostgreSQL usa SERIALIZABLE; SQLite ya serializa las
+// escrituras porque sus transacciones empiezan con BEGIN IMMEDIATE.
+func OpcionesTransaccion(db *gorm.DB) *sql.TxOptions {
+	if db.Dialector.Name() == "postgres" {
+		return &sql.TxOptions{Isolation: sql.LevelSerializable}
+	}
+	return nil
+}
+
+// EsConflicto indica si err es un conflicto de concurrencia que se resuelve
+// reintentando la transacción completa: base ocupada o bloqueada en SQLite,
+// fallo de serialización o deadlock en PostgreSQL.
+func EsConflicto(err error) bool {
+	var errSQLite sqlite3.Error
+	if errors.As(err, &errSQLite) {
+		return errSQLite.Code == sqlite3.ErrBusy || errSQLite.Code == sqlite3.ErrLocked
+	}
+
+	var errPostgres *pgconn.PgError
+	if errors.As(err, &errPostgres) {
+		return errPostgres.Code == "40001" || errPostgres.Code == "40P01"
+	}
+	return false
+}
+
+// EsDuplicado indica si err es una violación de una restricción de unicidad.
+func EsDuplicado(err error) bool {
+	var errSQLite sqlite3.Error
+	if errors.As(err, &errSQLite) {
+		return errSQLite.ExtendedCode == sqlite3.ErrConstraintUnique ||
+			errSQLite.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+	}
+
+	var errPostgres *pgconn.PgError
+	if errors.As(err, &errPostgres) {
+		return errPostgres.Code == "23505"
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"ProyectoIngeso/database"
+	"ProyectoIngeso/database/dbtest"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+)
+
+func TestClasificacionDeErrores(t *testing.T) {
+	casos := []struct {
+		nombre    string
+		err       error
+		conflicto bool
+		duplicado bool
+	}{
+		{"sqlite ocupada", sqlite3.Error{Code: sqlite3.ErrBusy}, true, false},
+		{"sqlite bloqueada", sqlite3.Error{Code: sqlite3.ErrLocked}, true, false},
+		{"sqlite unique", sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique}, false, true},
+		{"sqlite primary key", sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintPrimaryKey}, false, true},
+		{"sqlite foreign key", sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintForeignKey}, false, false},
+		{"postgres serialización", &pgconn.PgError{Code: "40001"}, true, false},
+		{"postgres deadlock", &pgconn.PgError{Code: "40P01"}, true, false},
+		{"postgres unique", &pgconn.PgError{Code: "23505"}, false, true},
+		{"postgres foreign key", &pgconn.PgError{Code: "23503"}, false, false},
+		{"envuelto", fmt.Errorf("al guardar: %w", &pgconn.PgError{Code: "40001"}), true, false},
+		{"otro", errors.New("falla"), false, false},
+		{"nil", nil, false, false},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			if got := database.EsConflicto(c.err); got != c.conflicto {
+				t.Errorf("EsConflicto = %v, se esperaba %v", got, c.conflicto)
+			}
+			if got := database.EsDuplicado(c.err); got != c.duplicado {
+				t.Errorf("EsDuplicado = %v, se esperaba %v", got, c.duplicado)
+			}
+		})
+	}
+}
+
+type fila struct {
+	ID    string `gorm:"primaryKey;type:text"`
+	Clave string `gorm:"type:text;uniqueIndex"`
+	Valor int
+}
+
+func crearTabla(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	if err := db.AutoMigrate(&fila{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEsDuplicadoConElDriver(t *testing.T) {
+	dbtest.ParaCadaMotor(t, func(t *testing.T, db *gorm.DB) {
+		crearTabla(t, db)
+		if err := db.Create(&fila{ID: "1", Clave: "a"}).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		err := db.Create(&fila{ID: "2", Clave: "a"}).Error
+		if !database.EsDuplicado(err) {
+			t.Errorf("clave única repetida: EsDuplicado(%v) = false", err)
+		}
+		err = db.Create(&fila{ID: "1", Clave: "b"}).Error
+		if !database.EsDuplicado(err) {
+			t.Errorf("clave primaria repetida: EsDuplicado(%v) = false", err)
+		}
+		if database.EsConflicto(err) {
+			t.Errorf("EsConflicto(%v) = true para un duplicado", err)
+		}
+	})
+}
+
+func TestEnTransaccion(t *testing.T) {
+	dbtest.ParaCadaMotor(t, func(t *testing.T, db *gorm.DB) {
+		crearTabla(t, db)
+		ctx := context.Background()
+
+		t.Run("revierte si fn falla", func(t *testing.T) {
+			errFn := errors.New("falla")
+			llamadas := 0
+			err := database.EnTransaccion(ctx, db, func(tx *gorm.DB) error {
+				llamadas++
+				if err := tx.Create(&fila{ID: "revertida", Clave: "revertida"}).Error; err != nil {
+					return err
+				}
+				return errFn
+			})
+			if !errors.Is(err, errFn) || llamadas != 1 {
+				t.Fatalf("EnTransaccion = %v tras %d llamadas", err, llamadas)
+			}
+			var total int64
+			db.Model(&fila{}).Where("id = ?", "revertida").Count(&total)
+			if total != 0 {
+				t.Error("la escritura de la transacción fallida quedó aplicada")
+			}
+		})
+
+		t.Run("reintenta conflictos", func(t *testing.T) {
+			llamadas := 0
+			err := database.EnTransaccion(ctx, db, func(tx *gorm.DB) error {
+				llamadas++
+				if llamadas == 1 {
+					return &pgconn.PgError{Code: "40001"}
+				}
+				return nil
+			})
+			if err != nil || llamadas != 2 {
+				t.Fatalf("EnTransaccion = %v tras %d llamadas", err, llamadas)
+			}
+		})
+
+		t.Run("escrituras concurrentes", func(t *testing.T) {
+			if err := db.Create(&fila{ID: "contador", Clave: "contador"}).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			const escritores = 4
+			var wg sync.WaitGroup
+			errs := make(chan error, escritores)
+			for range escritores {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					errs <- database.EnTransaccion(ctx, db, func(tx *gorm.DB) error {
+						var f fila
+						if err := tx.First(&f, "id = ?", "contador").Error; err != nil {
+							return err
+						}
+						return tx.Model(&f).Update("valor", f.Valor+1).Error
+					})
+				}()
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				if err != nil {
+					t.Error(err)
+				}
+			}
+
+			var f fila
+			if err := db.First(&f, "id = ?", "contador").Error; err != nil {
+				t.Fatal(err)
+			}
+			if f.Valor != escritores {
+				t.Errorf("contador = %d, se esperaba %d: se perdieron escrituras", f.Valor, escritores)
+			}
+		})
+	})
+}
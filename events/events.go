@@ -0,0 +1,126 @@
+// Package events define los eventos de dominio que el servicio de usuarios
+// anuncia a los demás servicios. Cada tipo de evento tiene un esquema JSON
+// versionado en schemas/; un cambio incompatible en los datos de un evento
+// se publica como una versión nueva, nunca modificando la existente.
+package events
+
+import (
+	"context"
+	"embed"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipos de evento. También son la routing key con la que se publican.
+const (
+	TipoUsuarioRegistrado   = "user.registered"
+	TipoEmailCambiado       = "user.email_changed"
+	TipoUsuarioEliminado    = "user.deleted"
+	TipoItemCarritoAgregado = "cart.item_added"
+	TipoCarritoVaciado      = "cart.cleared"
+	TipoInscripcionCreada   = "enrollment.created"
+)
+
+// Schemas contiene el esquema JSON de cada versión de cada evento, con
+// nombre <tipo>.v<versión>.json.
+//
+//go:embed schemas/*.json
+var Schemas embed.FS
+
+// Payload son los datos de un tipo y versión de evento.
+type Payload interface {
+	Tipo() string
+	Version() int
+}
+
+// Event es el sobre que se publica para todo evento; Data sigue el esquema
+// de Type en la versión Version.
+type Event struct {
+	ID         string  `json:"id"`
+	Type       string  `json:"type"`
+	Version    int     `json:"version"`
+	OccurredAt string  `json:"occurredAt"`
+	Data       Payload `json:"data"`
+}
+
+// New arma el evento para data con un ID nuevo y la hora actual.
+func New(data Payload) Event {
+	return Event{
+		ID:         uuid.NewString(),
+		Type:       data.Tipo(),
+		Version:    data.Version(),
+		OccurredAt: time.Now().UTC().Format(time.RFC3339),
+		Data:       data,
+	}
+}
+
+// Publisher abstrae el transporte de los eventos para que los resolvers no
+// dependan de RabbitMQ.
+type Publisher interface {
+	// Publish envía el evento. Un error significa que el evento no salió.
+	Publish(ctx context.Context, event Event) error
+}
+
+// UsuarioRegistradoV1 se publica al crear una cuenta.
+type UsuarioRegistradoV1 struct {
+	UserID       string `json:"userID"`
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	NameLastName string `json:"nameLastName"`
+}
+
+func (UsuarioRegistradoV1) Tipo() string { return TipoUsuarioRegistrado }
+func (UsuarioRegistradoV1) Version() int { return 1 }
+
+// EmailCambiadoV1 se publica cuando un usuario cambia su email.
+type EmailCambiadoV1 struct {
+	UserID        string `json:"userID"`
+	PreviousEmail string `json:"previousEmail"`
+	Email         string `json:"email"`
+}
+
+func (EmailCambiadoV1) Tipo() string { return TipoEmailCambiado }
+func (EmailCambiadoV1) Version() int { return 1 }
+
+// UsuarioEliminadoV1 se publica al eliminar una cuenta, junto con su
+// carrito e inscripciones.
+type UsuarioEliminadoV1 struct {
+	UserID string `json:"userID"`
+	Email  string `json:"email"`
+}
+
+func (UsuarioEliminadoV1) Tipo() string { return TipoUsuarioEliminado }
+func (UsuarioEliminadoV1) Version() int { return 1 }
+
+// ItemCarritoAgregadoV1 se publica al agregar un curso al carrito.
+type ItemCarritoAgregadoV1 struct {
+	UserID   string `json:"userID"`
+	CartID   string `json:"cartID"`
+	CourseID string `json:"courseID"`
+}
+
+func (ItemCarritoAgregadoV1) Tipo() string { return TipoItemCarritoAgregado }
+func (ItemCarritoAgregadoV1) Version() int { return 1 }
+
+// CarritoVaciadoV1 se publica al vaciar el carrito completo de un usuario.
+type CarritoVaciadoV1 struct {
+	UserID string `json:"userID"`
+}
+
+func (CarritoVaciadoV1) Tipo() string { return TipoCarritoVaciado }
+func (CarritoVaciadoV1) Version() int { return 1 }
+
+// InscripcionCreadaV1 se publica por cada curso que un usuario obtiene, sea
+// por compra, regalo o asignación de un administrador.
+type InscripcionCreadaV1 struct {
+	EnrollmentID string  `json:"enrollmentID"`
+	UserID       string  `json:"userID"`
+	CourseID     string  `json:"courseID"`
+	Source       string  `json:"source"`
+	PaymentID    *string `json:"paymentID,omitempty"` // Solo en compras
+	EnrolledAt   string  `json:"enrolledAt"`
+}
+
+func (InscripcionCreadaV1) Tipo() string { return TipoInscripcionCreada }
+func (InscripcionCreadaV1) Version() int { return 1 }
@@ -0,0 +1,88 @@
+package graph
+
+import (
+	"ProyectoIngeso/models"
+	"ProyectoIngeso/utils"
+	"context"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Códigos de error expuestos en extensions.code de la respuesta GraphQL
+const (
+	CodigoNoAutenticado = "UNAUTHENTICATED"
+	CodigoProhibido     = "FORBIDDEN"
+)
+
+// errNoAutenticado se devuelve cuando la operación requiere un token válido.
+func errNoAutenticado() error {
+	return &gqlerror.Error{
+		Message:    "se requiere autenticación",
+		Extensions: map[string]interface{}{"code": CodigoNoAutenticado},
+	}
+}
+
+// errProhibido se devuelve cuando el usuario autenticado intenta operar sobre
+// datos que no le pertenecen.
+func errProhibido() error {
+	return &gqlerror.Error{
+		Message:    "no tienes permiso para realizar esta operación",
+		Extensions: map[string]interface{}{"code": CodigoProhibido},
+	}
+}
+
+// usuarioActual obtiene el usuario autenticado de la petición.
+func usuarioActual(ctx context.Context) (*models.Usuario, error) {
+	usuario, ok := utils.UsuarioDesdeContexto(ctx)
+	if !ok {
+		return nil, errNoAutenticado()
+	}
+	return usuario, nil
+}
+
+func esAdmin(usuario *models.Usuario) bool {
+	return usuario.Role == models.RolAdmin
+}
+
+// autorizarSobre verifica que el usuario autenticado sea el dueño de userID o
+// un administrador.
+func autorizarSobre(ctx context.Context, userID string) (*models.Usuario, error) {
+	actual, err := usuarioActual(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if actual.UserID != userID && !esAdmin(actual) {
+		return nil, errProhibido()
+	}
+	return actual, nil
+}
+
+// usuarioObjetivo determina sobre qué usuario actúa una operación. Sin
+// identificador se usa el usuario autenticado; apuntar a otro usuario
+// requiere rol de administrador. buscar es la búsqueda del servicio de
+// usuarios que corresponde al identificador.
+func (r *Resolver) usuarioObjetivo(ctx context.Context, buscar func(context.Context, string) (*models.Usuario, error), valor *string) (*models.Usuario, error) {
+	actual, err := usuarioActual(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if valor == nil || *valor == "" {
+		// Copia para no modificar el usuario guardado en el contexto
+		copia := *actual
+		return &copia, nil
+	}
+
+	objetivo, err := buscar(ctx, *valor)
+	if err != nil {
+		// A quien no es administrador no se le revela si el usuario existe
+		if !esAdmin(actual) {
+			return nil, errProhibido()
+		}
+		return nil, err
+	}
+
+	if objetivo.UserID != actual.UserID && !esAdmin(actual) {
+		return nil, errProhibido()
+	}
+	return objetivo, nil
+}
@@ -0,0 +1,142 @@
+package graph
+
+import (
+	"ProyectoIngeso/courses"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Parámetros del agrupador de consultas al servicio de cursos.
+const (
+	esperaLoteCursos = 2 * time.Millisecond
+	maxLoteCursos    = 100
+)
+
+// CargadorCursos agrupa las búsquedas de cursos hechas durante una operación
+// GraphQL para resolverlas con una sola consulta al catálogo. No guarda
+// resultados entre lotes: de eso se encarga el caché del catálogo.
+type CargadorCursos struct {
+	catalogo courses.Catalog
+
+	mu   sync.Mutex
+	lote *loteCursos
+}
+
+type loteCursos struct {
+	ctx    context.Context
+	ids    []string
+	vistos map[string]bool
+	listo  chan struct{}
+	una    sync.Once
+
+	cursos map[string]*courses.Course
+	err    error
+}
+
+// NuevoCargadorCursos crea un cargador sobre catalogo.
+func NuevoCargadorCursos(catalogo courses.Catalog) *CargadorCursos {
+	return &CargadorCursos{catalogo: catalogo}
+}
+
+type claveCargadorCursos struct{}
+
+// ContextoConCargadorCursos guarda un cargador nuevo en ctx. Se llama una vez
+// por operación GraphQL.
+func ContextoConCargadorCursos(ctx context.Context, catalogo courses.Catalog) context.Context {
+	return context.WithValue(ctx, claveCargadorCursos{}, NuevoCargadorCursos(catalogo))
+}
+
+// cargadorCursos devuelve el cargador de la operación o, fuera de una
+// operación GraphQL, uno que no se comparte con nadie.
+func (r *Resolver) cargadorCursos(ctx context.Context) *CargadorCursos {
+	if cargador, ok := ctx.Value(claveCargadorCursos{}).(*CargadorCursos); ok {
+		return cargador
+	}
+	return NuevoCargadorCursos(r.Cursos)
+}
+
+// Cargar devuelve un curso, o nil si el catálogo no lo conoce.
+func (c *CargadorCursos) Cargar(ctx context.Context, courseID string) (*courses.Course, error) {
+	cursos, err := c.CargarVarios(ctx, []string{courseID})
+	if err != nil {
+		return nil, err
+	}
+	return cursos[courseID], nil
+}
+
+// CargarVarios suma los cursos al lote en curso y espera su resultado. Los
+// cursos inexistentes no aparecen en el mapa.
+func (c *CargadorCursos) CargarVarios(ctx context.Context, courseIDs []string) (map[string]*courses.Course, error) {
+	lotes := c.encolar(ctx, courseIDs)
+
+	cursos := make(map[string]*courses.Course, len(courseIDs))
+	for _, lote := range lotes {
+		select {
+		case <-lote.listo:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if lote.err != nil {
+			return nil, fmt.Errorf("error al consultar el servicio de cursos: %w", lote.err)
+		}
+		for _, courseID := range courseIDs {
+			if curso, ok := lote.cursos[courseID]; ok {
+				cursos[courseID] = curso
+			}
+		}
+	}
+	return cursos, nil
+}
+
+// encolar agrega los IDs al lote abierto y devuelve los lotes de los que
+// depende el resultado (más de uno si se alcanzó maxLoteCursos).
+func (c *CargadorCursos) encolar(ctx context.Context, courseIDs []string) []*loteCursos {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var lotes []*loteCursos
+	for _, courseID := range courseIDs {
+		if c.lote == nil {
+			c.lote = &loteCursos{
+				// El lote puede sobrevivir a quien lo abrió; su cancelación no
+				// debe afectar a los demás que esperan el mismo lote.
+				ctx:    context.WithoutCancel(ctx),
+				vistos: make(map[string]bool),
+				listo:  make(chan struct{}),
+			}
+			lote := c.lote
+			time.AfterFunc(esperaLoteCursos, func() { c.despachar(lote) })
+		}
+		lote := c.lote
+		if len(lotes) == 0 || lotes[len(lotes)-1] != lote {
+			lotes = append(lotes, lote)
+		}
+		if !lote.vistos[courseID] {
+			lote.vistos[courseID] = true
+			lote.ids = append(lote.ids, courseID)
+		}
+		if len(lote.ids) >= maxLoteCursos {
+			c.lote = nil
+			go c.despachar(lote)
+		}
+	}
+	return lotes
+}
+
+// despachar consulta el catálogo con los IDs del lote y despierta a quienes
+// lo esperan. Un lote lleno se despacha antes de que venza su espera; la
+// segunda llamada no hace nada.
+func (c *CargadorCursos) despachar(lote *loteCursos) {
+	c.mu.Lock()
+	if c.lote == lote {
+		c.lote = nil
+	}
+	c.mu.Unlock()
+
+	lote.una.Do(func() {
+		lote.cursos, lote.err = c.catalogo.GetCourses(lote.ctx, lote.ids)
+		close(lote.listo)
+	})
+}
@@ -0,0 +1,123 @@
+package graph
+
+import (
+	"ProyectoIngeso/courses"
+	"ProyectoIngeso/graph/model"
+	"ProyectoIngeso/models"
+	"context"
+	"fmt"
+	"math"
+)
+
+// CarritoDe devuelve una página del carrito del usuario ordenada por cartID.
+// itemCount refleja el carrito completo, no solo la página.
+func (r *Resolver) CarritoDe(ctx context.Context, userID string, first *int, after *string) (*model.Cart, error) {
+	limite, err := limitePagina(first)
+	if err != nil {
+		return nil, err
+	}
+
+	var despuesDe string
+	if after != nil && *after != "" {
+		if despuesDe, err = decodificarCursor(*after); err != nil {
+			return nil, err
+		}
+	}
+
+	pagina, err := r.Servicios.Carritos.Pagina(ctx, userID, despuesDe, limite)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el carrito: %v", err)
+	}
+
+	cart := &model.Cart{
+		UserID:      userID,
+		Items:       carritosGraphQL(pagina.Items),
+		ItemCount:   pagina.Total,
+		HasNextPage: pagina.HaySiguiente,
+	}
+	if n := len(cart.Items); n > 0 {
+		cursor := codificarCursor(cart.Items[n-1].CartID)
+		cart.EndCursor = &cursor
+	}
+	return cart, nil
+}
+
+// CarritoDeUsuario es la variante administrativa de CarritoDe; verifica que
+// el usuario exista antes de leer su carrito.
+func (r *Resolver) CarritoDeUsuario(ctx context.Context, userID string, first *int, after *string) (*model.Cart, error) {
+	if _, err := r.Servicios.Usuarios.PorID(ctx, userID); err != nil {
+		return nil, err
+	}
+	return r.CarritoDe(ctx, userID, first, after)
+}
+
+// SubtotalCarrito suma los precios actuales de todos los cursos del carrito.
+// Los precios se piden en una sola consulta, compartida con los campos course
+// de la misma operación. Los cursos que ya no existen no suman.
+func (r *Resolver) SubtotalCarrito(ctx context.Context, userID string) (float64, error) {
+	courseIDs, err := r.Servicios.Carritos.CourseIDs(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("error al obtener el carrito: %v", err)
+	}
+
+	cursos, err := r.cargadorCursos(ctx).CargarVarios(ctx, courseIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	var subtotal float64
+	for _, courseID := range courseIDs {
+		if curso, ok := cursos[courseID]; ok {
+			subtotal += curso.Price
+		}
+	}
+	return math.Round(subtotal*100) / 100, nil
+}
+
+// CursoDeItem devuelve los datos del curso de un item del carrito, o nil si
+// el curso ya no existe en el servicio de cursos.
+func (r *Resolver) CursoDeItem(ctx context.Context, item *model.Carrito) (*model.Course, error) {
+	curso, err := r.cargadorCursos(ctx).Cargar(ctx, item.CourseID)
+	if err != nil || curso == nil {
+		return nil, err
+	}
+	return cursoGraphQL(curso), nil
+}
+
+// cursoGraphQL convierte un curso del catálogo al tipo de GraphQL.
+func cursoGraphQL(curso *courses.Course) *model.Course {
+	return &model.Course{
+		CourseID:   curso.ID,
+		Title:      curso.Title,
+		Price:      curso.Price,
+		Thumbnail:  stringOpcional(curso.Thumbnail),
+		Instructor: stringOpcional(curso.Instructor),
+	}
+}
+
+// itemsCarrito devuelve el carrito completo del usuario como tipos de GraphQL.
+func (r *Resolver) itemsCarrito(ctx context.Context, userID string) ([]*model.Carrito, error) {
+	items, err := r.Servicios.Carritos.Items(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el carrito: %v", err)
+	}
+	return carritosGraphQL(items), nil
+}
+
+// carritoGraphQL convierte un item del carrito al tipo de GraphQL. El curso
+// lo resuelve CursoDeItem solo si la consulta lo pide.
+func carritoGraphQL(item *models.Carrito) *model.Carrito {
+	return &model.Carrito{
+		CartID:   item.CartID,
+		UserID:   item.UserID,
+		CourseID: item.CourseID,
+	}
+}
+
+func carritosGraphQL(items []models.Carrito) []*model.Carrito {
+	result := make([]*model.Carrito, 0, len(items))
+	for i := range items {
+		result = append(result, carritoGraphQL(&items[i]))
+	}
+	return result
+}
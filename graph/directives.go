@@ -0,0 +1,39 @@
+package graph
+
+import (
+	"ProyectoIngeso/graph/model"
+	"ProyectoIngeso/models"
+	"context"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// nivelRol ordena los roles de menor a mayor privilegio.
+var nivelRol = map[string]int{
+	models.RolUsuario:    1,
+	models.RolInstructor: 2,
+	models.RolAdmin:      3,
+}
+
+// HasRole implementa la directiva @hasRole del esquema.
+func HasRole(ctx context.Context, obj interface{}, next graphql.Resolver, role model.Role) (interface{}, error) {
+	usuario, err := usuarioActual(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !tieneRol(usuario, rolDesdeGraphQL(role)) {
+		return nil, errProhibido()
+	}
+	return next(ctx)
+}
+
+// tieneRol indica si el usuario tiene el rol requerido o uno superior.
+func tieneRol(usuario *models.Usuario, requerido string) bool {
+	return nivelRol[usuario.Role] >= nivelRol[requerido]
+}
+
+// rolDesdeGraphQL convierte el enum Role al valor guardado en models.Usuario.
+func rolDesdeGraphQL(role model.Role) string {
+	return strings.ToLower(string(role))
+}
@@ -0,0 +1,33 @@
+package graph
+
+import (
+	"ProyectoIngeso/events"
+	"ProyectoIngeso/models"
+	"context"
+	"log"
+)
+
+// emitirEvento publica el evento de dominio para los demás servicios. Se
+// llama después de confirmar la operación; un error al publicar no la
+// revierte.
+func (r *Resolver) emitirEvento(ctx context.Context, data events.Payload) {
+	if r.Eventos == nil {
+		return
+	}
+	evento := events.New(data)
+	if err := r.Eventos.Publish(ctx, evento); err != nil {
+		log.Printf("No se pudo publicar el evento %s %s: %s", evento.Type, evento.ID, err)
+	}
+}
+
+// emitirInscripcion publica enrollment.created para la inscripción.
+func (r *Resolver) emitirInscripcion(ctx context.Context, inscripcion *models.UsuarioCurso) {
+	r.emitirEvento(ctx, events.InscripcionCreadaV1{
+		EnrollmentID: inscripcion.ID,
+		UserID:       inscripcion.UserID,
+		CourseID:     inscripcion.CourseID,
+		Source:       inscripcion.Source,
+		PaymentID:    inscripcion.PaymentID,
+		EnrolledAt:   inscripcion.EnrolledAt,
+	})
+}
@@ -0,0 +1,20 @@
+package graph
+
+import (
+	"ProyectoIngeso/graph/model"
+	"ProyectoIngeso/models"
+)
+
+// inscripcionGraphQL convierte una inscripción al tipo de GraphQL. email es
+// el del dueño, que ya no se guarda en la inscripción.
+func inscripcionGraphQL(inscripcion *models.UsuarioCurso, email string) *model.UsuarioCurso {
+	return &model.UsuarioCurso{
+		ID:         inscripcion.ID,
+		UserID:     inscripcion.UserID,
+		Email:      email,
+		CourseID:   inscripcion.CourseID,
+		EnrolledAt: inscripcion.EnrolledAt,
+		Source:     inscripcion.Source,
+		PaymentID:  inscripcion.PaymentID,
+	}
+}
@@ -2,29 +2,165 @@
 
 package model
 
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type AuthPayload struct {
+	Token        string   `json:"token"`
+	RefreshToken string   `json:"refreshToken"`
+	ExpiresAt    string   `json:"expiresAt"`
+	Usuario      *Usuario `json:"usuario"`
+}
+
 type Carrito struct {
-	CartID   string `json:"cartID"`
-	UserID   string `json:"userID"`
-	CourseID string `json:"courseID"`
+	CartID   string  `json:"cartID"`
+	UserID   string  `json:"userID"`
+	CourseID string  `json:"courseID"`
+	Course   *Course `json:"course,omitempty"`
+}
+
+type Cart struct {
+	UserID      string     `json:"userID"`
+	Items       []*Carrito `json:"items"`
+	ItemCount   int        `json:"itemCount"`
+	Subtotal    float64    `json:"subtotal"`
+	EndCursor   *string    `json:"endCursor,omitempty"`
+	HasNextPage bool       `json:"hasNextPage"`
+}
+
+type CartUpdate struct {
+	UserID   string     `json:"userID"`
+	Action   string     `json:"action"`
+	CourseID *string    `json:"courseID,omitempty"`
+	Items    []*Carrito `json:"items"`
+}
+
+type Course struct {
+	CourseID   string  `json:"courseID"`
+	Title      string  `json:"title"`
+	Price      float64 `json:"price"`
+	Thumbnail  *string `json:"thumbnail,omitempty"`
+	Instructor *string `json:"instructor,omitempty"`
+}
+
+type CourseRatingSummary struct {
+	CourseID  string  `json:"courseID"`
+	Average   float64 `json:"average"`
+	Count     int     `json:"count"`
+	Histogram []int   `json:"histogram"`
 }
 
 type Mutation struct {
 }
 
+type Notificacion struct {
+	NotificationID string `json:"notificationID"`
+	Type           string `json:"type"`
+	Message        string `json:"message"`
+	Status         string `json:"status"`
+	CreatedAt      string `json:"createdAt"`
+}
+
+type NotificacionConnection struct {
+	Nodes       []*Notificacion `json:"nodes"`
+	EndCursor   *string         `json:"endCursor,omitempty"`
+	HasNextPage bool            `json:"hasNextPage"`
+}
+
+type Pago struct {
+	PaymentID     string      `json:"paymentID"`
+	UserID        string      `json:"userID"`
+	Amount        float64     `json:"amount"`
+	Status        string      `json:"status"`
+	PaymentMethod string      `json:"paymentMethod"`
+	PaymentDate   string      `json:"paymentDate"`
+	Items         []*PagoItem `json:"items"`
+}
+
+type PagoItem struct {
+	ItemID   string  `json:"itemID"`
+	CourseID string  `json:"courseID"`
+	Price    float64 `json:"price"`
+}
+
 type Query struct {
 }
 
+type Resena struct {
+	ReviewID  string  `json:"reviewID"`
+	UserID    string  `json:"userID"`
+	CourseID  string  `json:"courseID"`
+	Rating    int     `json:"rating"`
+	Comments  string  `json:"comments"`
+	CreatedAt string  `json:"createdAt"`
+	UpdatedAt string  `json:"updatedAt"`
+	Reply     *string `json:"reply,omitempty"`
+	RepliedAt *string `json:"repliedAt,omitempty"`
+}
+
+type Subscription struct {
+}
+
 type Usuario struct {
 	UserID       string `json:"userID"`
 	NameLastName string `json:"nameLastName"`
 	Username     string `json:"username"`
 	Email        string `json:"email"`
-	Password     string `json:"password"`
 	Role         string `json:"role"`
 }
 
 type UsuarioCurso struct {
-	ID       string `json:"id"`
-	Email    string `json:"email"`
-	CourseID string `json:"courseID"`
+	ID         string  `json:"id"`
+	UserID     string  `json:"userID"`
+	Email      string  `json:"email"`
+	CourseID   string  `json:"courseID"`
+	EnrolledAt string  `json:"enrolledAt"`
+	Source     string  `json:"source"`
+	PaymentID  *string `json:"paymentID,omitempty"`
+}
+
+type Role string
+
+const (
+	RoleAdmin      Role = "ADMIN"
+	RoleInstructor Role = "INSTRUCTOR"
+	RoleUser       Role = "USER"
+)
+
+var AllRole = []Role{
+	RoleAdmin,
+	RoleInstructor,
+	RoleUser,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleAdmin, RoleInstructor, RoleUser:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
 }
@@ -0,0 +1,150 @@
+package graph
+
+import (
+	"ProyectoIngeso/graph/model"
+	"ProyectoIngeso/models"
+	"ProyectoIngeso/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// notificar guarda una notificación para el usuario. Recibe el repositorio a
+// usar para poder participar de la transacción de quien genera el evento;
+// quien llama debe publicarla cuando la transacción se confirme.
+func notificar(ctx context.Context, notificaciones repository.NotificationRepository, userID string, tipo string, mensaje string) (*models.Notificación, error) {
+	notificacion := models.Notificación{
+		NotificationID: generateUniqueID(),
+		UserID:         userID,
+		Type:           tipo,
+		Message:        mensaje,
+		Status:         models.NotificacionNoLeida,
+		CreatedAt:      time.Now().UTC().Format(time.RFC3339),
+	}
+	if err := notificaciones.Crear(ctx, &notificacion); err != nil {
+		return nil, err
+	}
+	return &notificacion, nil
+}
+
+// notificarSinFallar registra la notificación fuera de una transacción. Un
+// error al notificar no debe revertir la operación que ya se completó.
+func (r *Resolver) notificarSinFallar(ctx context.Context, userID string, tipo string, mensaje string) {
+	notificacion, err := notificar(ctx, r.Store.Repositorios().Notificaciones, userID, tipo, mensaje)
+	if err != nil {
+		log.Printf("No se pudo crear la notificación %s para el usuario %s: %s", tipo, userID, err)
+		return
+	}
+	r.publicarNotificacion(notificacion)
+}
+
+// MisNotificaciones pagina las notificaciones del usuario autenticado, de la
+// más reciente a la más antigua. after es el endCursor de la página anterior.
+func (r *Resolver) MisNotificaciones(ctx context.Context, estado *string, first *int, after *string) (*model.NotificacionConnection, error) {
+	usuario, err := usuarioActual(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	limite, err := limitePagina(first)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtro, fecha, id string
+	if estado != nil {
+		filtro = *estado
+	}
+	if after != nil && *after != "" {
+		if fecha, id, err = decodificarCursorCompuesto(*after); err != nil {
+			return nil, err
+		}
+	}
+
+	// Se pide un elemento extra para saber si hay otra página
+	notificaciones, err := r.Store.Repositorios().Notificaciones.Pagina(ctx, usuario.UserID, filtro, fecha, id, limite+1)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener las notificaciones: %v", err)
+	}
+
+	conexion := &model.NotificacionConnection{
+		Nodes:       []*model.Notificacion{},
+		HasNextPage: len(notificaciones) > limite,
+	}
+	if conexion.HasNextPage {
+		notificaciones = notificaciones[:limite]
+	}
+	for i := range notificaciones {
+		conexion.Nodes = append(conexion.Nodes, notificacionGraphQL(&notificaciones[i]))
+	}
+	if n := len(notificaciones); n > 0 {
+		cursor := codificarCursor(notificaciones[n-1].CreatedAt + "|" + notificaciones[n-1].NotificationID)
+		conexion.EndCursor = &cursor
+	}
+	return conexion, nil
+}
+
+// ContarNoLeidas devuelve cuántas notificaciones sin leer tiene el usuario autenticado.
+func (r *Resolver) ContarNoLeidas(ctx context.Context) (int, error) {
+	usuario, err := usuarioActual(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	total, err := r.Store.Repositorios().Notificaciones.ContarNoLeidas(ctx, usuario.UserID)
+	if err != nil {
+		return 0, fmt.Errorf("error al contar las notificaciones: %v", err)
+	}
+	return total, nil
+}
+
+// MarcarNotificacionLeida marca como leída una notificación propia.
+func (r *Resolver) MarcarNotificacionLeida(ctx context.Context, notificationID string) (*models.Notificación, error) {
+	usuario, err := usuarioActual(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	notificaciones := r.Store.Repositorios().Notificaciones
+	notificacion, err := notificaciones.PorID(ctx, notificationID)
+	if err != nil {
+		return nil, errors.New("notificación no encontrada")
+	}
+	if notificacion.UserID != usuario.UserID {
+		return nil, errProhibido()
+	}
+
+	notificacion.Status = models.NotificacionLeida
+	if err := notificaciones.MarcarLeida(ctx, notificacion.NotificationID); err != nil {
+		return nil, errors.New("no se pudo actualizar la notificación")
+	}
+	return notificacion, nil
+}
+
+// MarcarTodasLeidas marca como leídas todas las notificaciones del usuario
+// autenticado y devuelve cuántas cambiaron.
+func (r *Resolver) MarcarTodasLeidas(ctx context.Context) (int, error) {
+	usuario, err := usuarioActual(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	cambiadas, err := r.Store.Repositorios().Notificaciones.MarcarTodasLeidas(ctx, usuario.UserID)
+	if err != nil {
+		return 0, errors.New("no se pudieron actualizar las notificaciones")
+	}
+	return cambiadas, nil
+}
+
+// notificacionGraphQL convierte una notificación de la base de datos al tipo de GraphQL.
+func notificacionGraphQL(notificacion *models.Notificación) *model.Notificacion {
+	return &model.Notificacion{
+		NotificationID: notificacion.NotificationID,
+		Type:           notificacion.Type,
+		Message:        notificacion.Message,
+		Status:         notificacion.Status,
+		CreatedAt:      notificacion.CreatedAt,
+	}
+}
@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Límites comunes de las consultas paginadas
+const (
+	elementosPorPagina = 20
+	maxElementosPagina = 100
+)
+
+// limitePagina valida el argumento first de una consulta paginada.
+func limitePagina(first *int) (int, error) {
+	limite := elementosPorPagina
+	if first != nil {
+		limite = *first
+	}
+	if limite < 1 || limite > maxElementosPagina {
+		return 0, fmt.Errorf("first debe estar entre 1 y %d", maxElementosPagina)
+	}
+	return limite, nil
+}
+
+// codificarCursor arma un cursor opaco a partir de la clave de orden.
+func codificarCursor(clave string) string {
+	return base64.URLEncoding.EncodeToString([]byte(clave))
+}
+
+func decodificarCursor(cursor string) (string, error) {
+	crudo, err := base64.URLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", errors.New("cursor inválido")
+	}
+	return string(crudo), nil
+}
+
+// decodificarCursorCompuesto separa un cursor de la forma "fecha|id".
+func decodificarCursorCompuesto(cursor string) (string, string, error) {
+	clave, err := decodificarCursor(cursor)
+	if err != nil {
+		return "", "", err
+	}
+	fecha, id, ok := strings.Cut(clave, "|")
+	if !ok {
+		return "", "", errors.New("cursor inválido")
+	}
+	return fecha, id, nil
+}
@@ -0,0 +1,369 @@
+package graph
+
+import (
+	"ProyectoIngeso/courses"
+	"ProyectoIngeso/graph/model"
+	"ProyectoIngeso/models"
+	"ProyectoIngeso/payments"
+	"ProyectoIngeso/repository"
+	"ProyectoIngeso/services"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"time"
+)
+
+// monedaPagos es la moneda en que se cobran los cursos.
+const monedaPagos = "USD"
+
+// Checkout convierte el carrito del usuario autenticado en un pago y lo cobra
+// a través de la pasarela configurada. El precio de cada curso se congela en
+// sus items al momento de la compra. Los cursos que el usuario ya tiene no se
+// cobran, y un usuario no puede tener dos pagos pendientes a la vez.
+func (r *Resolver) Checkout(ctx context.Context, paymentMethod string, cardToken string) (*models.Pago, error) {
+	usuario, err := usuarioActual(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.Pagos == nil {
+		return nil, errors.New("pasarela de pagos no configurada")
+	}
+
+	carrito, err := r.Servicios.Carritos.CourseIDs(ctx, usuario.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el carrito: %v", err)
+	}
+	if len(carrito) == 0 {
+		return nil, errors.New("el carrito está vacío")
+	}
+
+	// Los precios se consultan fuera de la transacción, que puede reintentarse
+	cursos, err := r.catalogoPrecios().GetCourses(ctx, carrito)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener los precios de los cursos: %w", err)
+	}
+
+	pago := models.Pago{
+		PaymentID:     generateUniqueID(),
+		UserID:        usuario.UserID,
+		Status:        models.EstadoPagoPendiente,
+		PaymentMethod: paymentMethod,
+		PaymentDate:   time.Now().UTC().Format(time.RFC3339),
+	}
+
+	err = r.Store.EnTransaccion(ctx, func(repos repository.Repositorios) error {
+		pendientes, err := repos.Pagos.ContarPendientes(ctx, usuario.UserID)
+		if err != nil {
+			return err
+		}
+		if pendientes > 0 {
+			return errors.New("ya tienes un pago pendiente; espera a que se resuelva")
+		}
+
+		// El carrito se vuelve a leer dentro de la transacción
+		items, err := repos.Carritos.Items(ctx, usuario.UserID)
+		if err != nil {
+			return fmt.Errorf("error al obtener el carrito: %v", err)
+		}
+
+		pago.Items = nil
+		var total float64
+		var yaInscritos []string
+		for _, item := range items {
+			inscrito, err := repos.Inscripciones.EstaInscrito(ctx, usuario.UserID, item.CourseID)
+			if err != nil {
+				return err
+			}
+			if inscrito {
+				yaInscritos = append(yaInscritos, item.CourseID)
+				continue
+			}
+
+			curso, ok := cursos[item.CourseID]
+			if !ok {
+				if slices.Contains(carrito, item.CourseID) {
+					return fmt.Errorf("curso con ID %s no encontrado", item.CourseID)
+				}
+				return errors.New("el carrito cambió durante la compra; inténtalo de nuevo")
+			}
+			pago.Items = append(pago.Items, models.PagoItem{
+				ItemID:    generateUniqueID(),
+				PaymentID: pago.PaymentID,
+				CourseID:  item.CourseID,
+				Price:     curso.Price,
+			})
+			total += curso.Price
+		}
+		if len(pago.Items) == 0 {
+			return errors.New("ya tienes todos los cursos del carrito")
+		}
+		// Redondear a centavos para evitar arrastrar errores de punto flotante
+		pago.Amount = math.Round(total*100) / 100
+
+		// Crea el pago junto con sus items
+		if err := repos.Pagos.Crear(ctx, &pago); err != nil {
+			return fmt.Errorf("no se pudo crear el pago: %v", err)
+		}
+
+		// Los cursos que el usuario obtuvo después de agregarlos ya no van en el carrito
+		return repos.Carritos.Quitar(ctx, usuario.UserID, yaInscritos...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Sin monto no hay nada que cobrar en la pasarela
+	if pago.Amount == 0 {
+		return r.AprobarPago(ctx, pago.PaymentID)
+	}
+	return r.cobrarPago(ctx, &pago, cardToken)
+}
+
+// catalogoPrecios es el catálogo del que se toman los precios a cobrar: sin
+// caché, para cobrar el precio vigente.
+func (r *Resolver) catalogoPrecios() courses.Catalog {
+	if r.Precios != nil {
+		return r.Precios
+	}
+	return r.Cursos
+}
+
+// cobrarPago crea y captura la intención de cobro de un pago pendiente y
+// registra el resultado. Si la intención no se crea o no se puede guardar su
+// referencia, el pago se rechaza: ningún webhook podría encontrarlo y quedaría
+// bloqueando los siguientes checkouts. Si falla la captura, el pago queda
+// pendiente y se resolverá con el webhook correspondiente.
+func (r *Resolver) cobrarPago(ctx context.Context, pago *models.Pago, cardToken string) (*models.Pago, error) {
+	intent, err := r.Pagos.CreateIntent(ctx, payments.IntentRequest{
+		Amount:    pago.Amount,
+		Currency:  monedaPagos,
+		CardToken: cardToken,
+		Reference: pago.PaymentID,
+	})
+	if err != nil {
+		r.descartarPago(ctx, pago.PaymentID)
+		return nil, fmt.Errorf("error al iniciar el cobro: %v", err)
+	}
+
+	if err := r.Store.Repositorios().Pagos.AsignarReferencia(ctx, pago.PaymentID, intent.ID); err != nil {
+		// La intención queda sin capturar en la pasarela, así que no hay cobro
+		r.descartarPago(ctx, pago.PaymentID)
+		return nil, fmt.Errorf("no se pudo registrar la referencia del cobro: %v", err)
+	}
+
+	if intent.Status == payments.IntentRechazada {
+		return r.RechazarPago(ctx, pago.PaymentID)
+	}
+
+	if _, err := r.Pagos.Capture(ctx, intent.ID); err != nil {
+		return nil, fmt.Errorf("error al capturar el cobro: %v", err)
+	}
+
+	return r.AprobarPago(ctx, pago.PaymentID)
+}
+
+// descartarPago rechaza un pago que no llegó a tener una intención de cobro
+// utilizable. El error original es el que se informa al usuario.
+func (r *Resolver) descartarPago(ctx context.Context, paymentID string) {
+	if _, err := r.RechazarPago(ctx, paymentID); err != nil {
+		log.Printf("No se pudo rechazar el pago %s sin intención de cobro: %s", paymentID, err)
+	}
+}
+
+// AprobarPago marca un pago pendiente como aprobado. En la misma transacción
+// inscribe al usuario en los cursos pagados y los quita de su carrito.
+func (r *Resolver) AprobarPago(ctx context.Context, paymentID string) (*models.Pago, error) {
+	var notificacion *models.Notificación
+	var inscripciones []*models.UsuarioCurso
+	pago, err := r.transicionarPago(ctx, paymentID, models.EstadoPagoPendiente, models.EstadoPagoAprobado, func(ctx context.Context, repos repository.Repositorios, pago *models.Pago) error {
+		inscripciones = nil // La unidad de trabajo puede reintentarse
+		courseIDs := make([]string, 0, len(pago.Items))
+		for _, item := range pago.Items {
+			courseIDs = append(courseIDs, item.CourseID)
+
+			// Un curso ya inscrito (por ejemplo, regalado) no se duplica
+			inscrito, err := repos.Inscripciones.EstaInscrito(ctx, pago.UserID, item.CourseID)
+			if err != nil {
+				return err
+			}
+			if inscrito {
+				continue
+			}
+
+			inscripcion, err := services.CrearInscripcion(ctx, repos.Inscripciones, pago.UserID, item.CourseID, models.InscripcionCompra, &pago.PaymentID)
+			if err != nil {
+				return err
+			}
+			inscripciones = append(inscripciones, inscripcion)
+		}
+
+		// Solo se quitan los cursos pagados; lo agregado después del checkout se conserva
+		if err := repos.Carritos.Quitar(ctx, pago.UserID, courseIDs...); err != nil {
+			return err
+		}
+
+		var err error
+		notificacion, err = notificar(ctx, repos.Notificaciones, pago.UserID, models.NotificacionCursoComprado,
+			fmt.Sprintf("Tu compra de %d curso(s) por %.2f %s fue aprobada.", len(pago.Items), pago.Amount, monedaPagos))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Ya estaba aprobado: no hay nada nuevo que publicar
+	if notificacion == nil {
+		return pago, nil
+	}
+
+	// Publicar solo después de confirmar la transacción
+	r.publicarNotificacion(notificacion)
+	r.publicarCarrito(pago.UserID, AccionCarritoComprado, "")
+	for _, inscripcion := range inscripciones {
+		r.emitirInscripcion(ctx, inscripcion)
+	}
+	return pago, nil
+}
+
+// RechazarPago marca un pago pendiente como rechazado sin tocar el carrito.
+func (r *Resolver) RechazarPago(ctx context.Context, paymentID string) (*models.Pago, error) {
+	return r.transicionarPago(ctx, paymentID, models.EstadoPagoPendiente, models.EstadoPagoRechazado, nil)
+}
+
+// ReembolsarPago retira las inscripciones a los cursos pagados, marca el
+// pago como reembolsado y después devuelve el monto a través de la pasarela.
+// El reembolso externo va fuera de la transacción; como la pasarela lo
+// aplica una sola vez por GatewayRef, si falla basta con volver a llamar a
+// ReembolsarPago sobre el pago ya reembolsado.
+func (r *Resolver) ReembolsarPago(ctx context.Context, paymentID string) (*models.Pago, error) {
+	if r.Pagos == nil {
+		return nil, errors.New("pasarela de pagos no configurada")
+	}
+
+	pago, err := r.transicionarPago(ctx, paymentID, models.EstadoPagoAprobado, models.EstadoPagoReembolsado, retirarInscripciones)
+	if err != nil {
+		return nil, err
+	}
+
+	// Un pago sin monto se aprobó sin pasar por la pasarela
+	if pago.Amount == 0 || pago.GatewayRef == "" {
+		return pago, nil
+	}
+	if _, err := r.Pagos.Refund(ctx, pago.GatewayRef, pago.Amount); err != nil {
+		return nil, fmt.Errorf("el pago quedó reembolsado pero la pasarela no devolvió el dinero; reintenta el reembolso: %v", err)
+	}
+	return pago, nil
+}
+
+// ProcesarEventoPago aplica un webhook verificado de la pasarela. Los eventos
+// repetidos se ignoran para que el proveedor pueda reintentar sin efectos.
+func (r *Resolver) ProcesarEventoPago(ctx context.Context, evento *payments.WebhookEvent) error {
+	pago, err := r.Store.Repositorios().Pagos.PorReferencia(ctx, evento.Reference, evento.IntentID)
+	if err != nil {
+		return errors.New("pago no encontrado")
+	}
+
+	switch {
+	case evento.Type == payments.EventoPagoExitoso && pago.Status == models.EstadoPagoPendiente:
+		_, err = r.AprobarPago(ctx, pago.PaymentID)
+	case evento.Type == payments.EventoPagoRechazado && pago.Status == models.EstadoPagoPendiente:
+		_, err = r.RechazarPago(ctx, pago.PaymentID)
+	case evento.Type == payments.EventoPagoReembolsado && pago.Status == models.EstadoPagoAprobado:
+		// El proveedor ya devolvió el dinero; solo queda reflejarlo localmente
+		_, err = r.transicionarPago(ctx, pago.PaymentID, models.EstadoPagoAprobado, models.EstadoPagoReembolsado, retirarInscripciones)
+	}
+	return err
+}
+
+// retirarInscripciones elimina las inscripciones creadas por un pago. Las
+// que el usuario ya tenía por otro origen se conservan.
+func retirarInscripciones(ctx context.Context, repos repository.Repositorios, pago *models.Pago) error {
+	return repos.Inscripciones.RetirarPorPago(ctx, pago.UserID, pago.PaymentID)
+}
+
+// transicionarPago cambia el estado de un pago de desde a hacia como una
+// unidad de trabajo, ejecutando efecto antes de guardar el nuevo estado. Si
+// el pago ya está en hacia, por ejemplo porque el webhook y el cobro
+// sincrónico compiten, no hace nada y devuelve el pago sin error.
+func (r *Resolver) transicionarPago(ctx context.Context, paymentID string, desde string, hacia string, efecto func(ctx context.Context, repos repository.Repositorios, pago *models.Pago) error) (*models.Pago, error) {
+	var pago *models.Pago
+	err := r.Store.EnTransaccion(ctx, func(repos repository.Repositorios) error {
+		var err error
+		pago, err = repos.Pagos.PorID(ctx, paymentID)
+		if errors.Is(err, repository.ErrNoEncontrado) {
+			return errors.New("pago no encontrado")
+		}
+		if err != nil {
+			return err
+		}
+		if pago.Status == hacia {
+			return nil
+		}
+		if pago.Status != desde {
+			return fmt.Errorf("el pago no se puede pasar a %s desde el estado %s", hacia, pago.Status)
+		}
+
+		if efecto != nil {
+			if err := efecto(ctx, repos, pago); err != nil {
+				return err
+			}
+		}
+
+		pago.Status = hacia
+		return repos.Pagos.CambiarEstado(ctx, paymentID, hacia)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pago, nil
+}
+
+// MisPagos devuelve los pagos del usuario autenticado, del más reciente al más antiguo.
+func (r *Resolver) MisPagos(ctx context.Context) ([]models.Pago, error) {
+	usuario, err := usuarioActual(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pagos, err := r.Store.Repositorios().Pagos.DeUsuario(ctx, usuario.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener los pagos: %v", err)
+	}
+	return pagos, nil
+}
+
+// PagoPorID devuelve un pago si pertenece al usuario autenticado o si este es administrador.
+func (r *Resolver) PagoPorID(ctx context.Context, paymentID string) (*models.Pago, error) {
+	pago, err := r.Store.Repositorios().Pagos.PorID(ctx, paymentID)
+	if err != nil {
+		return nil, errors.New("pago no encontrado")
+	}
+	if _, err := autorizarSobre(ctx, pago.UserID); err != nil {
+		return nil, err
+	}
+	return pago, nil
+}
+
+// pagoGraphQL convierte un pago de la base de datos al tipo de GraphQL.
+func pagoGraphQL(pago *models.Pago) *model.Pago {
+	items := make([]*model.PagoItem, 0, len(pago.Items))
+	for _, item := range pago.Items {
+		items = append(items, &model.PagoItem{
+			ItemID:   item.ItemID,
+			CourseID: item.CourseID,
+			Price:    item.Price,
+		})
+	}
+	return &model.Pago{
+		PaymentID:     pago.PaymentID,
+		UserID:        pago.UserID,
+		Amount:        pago.Amount,
+		Status:        pago.Status,
+		PaymentMethod: pago.PaymentMethod,
+		PaymentDate:   pago.PaymentDate,
+		Items:         items,
+	}
+}
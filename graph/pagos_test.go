@@ -0,0 +1,39 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"ProyectoIngeso/courses"
+	"ProyectoIngeso/models"
+	"ProyectoIngeso/payments"
+)
+
+func TestCheckoutRechazaElPagoSiLaPasarelaFalla(t *testing.T) {
+	paraCadaStore(t, func(t *testing.T, r *Resolver, _ *courses.FakeCatalog) {
+		ctx, ana := registrar(t, r, "ana")
+		if _, err := r.Servicios.Carritos.Agregar(ctx, ana.UserID, "c1"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := r.Checkout(ctx, "tarjeta", payments.TokenFallaPasarela); err == nil || !strings.Contains(err.Error(), "error al iniciar el cobro") {
+			t.Fatalf("Checkout con la pasarela caída = %v", err)
+		}
+		pagos, err := r.MisPagos(ctx)
+		if err != nil || len(pagos) != 1 || pagos[0].Status != models.EstadoPagoRechazado {
+			t.Fatalf("pagos tras la falla = %+v, %v", pagos, err)
+		}
+
+		// El pago rechazado no bloquea un nuevo intento
+		pago, err := r.Checkout(ctx, "tarjeta", "tok_visa")
+		if err != nil {
+			t.Fatalf("Checkout tras la falla = %v", err)
+		}
+		if pago.Status != models.EstadoPagoAprobado {
+			t.Errorf("pago del segundo intento = %+v", pago)
+		}
+		if inscrito, err := r.Servicios.Inscripciones.EstaInscrito(ctx, ana.UserID, "c1"); err != nil || !inscrito {
+			t.Errorf("inscripción tras el segundo intento = %v, %v", inscrito, err)
+		}
+	})
+}
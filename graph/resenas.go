@@ -0,0 +1,229 @@
+package graph
+
+import (
+	"ProyectoIngeso/graph/model"
+	"ProyectoIngeso/models"
+	"ProyectoIngeso/repository"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Rango permitido para la calificación de una reseña.
+const (
+	calificacionMinima = 1
+	calificacionMaxima = 5
+)
+
+// CrearResena publica la reseña del usuario autenticado sobre un curso que
+// ya tiene inscrito. Cada usuario puede reseñar un curso una sola vez.
+func (r *Resolver) CrearResena(ctx context.Context, courseID string, rating int, comments *string) (*models.Reseña, error) {
+	usuario, err := usuarioActual(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validarCalificacion(rating); err != nil {
+		return nil, err
+	}
+
+	ahora := time.Now().UTC().Format(time.RFC3339)
+	resena := models.Reseña{
+		ReviewID:  generateUniqueID(),
+		UserID:    usuario.UserID,
+		CourseID:  courseID,
+		Rating:    rating,
+		CreatedAt: ahora,
+		UpdatedAt: ahora,
+	}
+	if comments != nil {
+		resena.Comments = *comments
+	}
+
+	// Las verificaciones y la creación van juntas para que dos envíos
+	// simultáneos no dejen dos reseñas del mismo curso
+	err = r.Store.EnTransaccion(ctx, func(repos repository.Repositorios) error {
+		// Solo quien tiene el curso puede reseñarlo
+		inscrito, err := repos.Inscripciones.EstaInscrito(ctx, usuario.UserID, courseID)
+		if err != nil {
+			return err
+		}
+		if !inscrito {
+			return errProhibido()
+		}
+
+		existente, err := repos.Resenas.Existe(ctx, usuario.UserID, courseID)
+		if err != nil {
+			return fmt.Errorf("error al verificar reseñas previas: %v", err)
+		}
+		if existente {
+			return errors.New("ya publicaste una reseña para este curso")
+		}
+
+		if err := repos.Resenas.Crear(ctx, &resena); err != nil {
+			return errors.New("no se pudo crear la reseña")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &resena, nil
+}
+
+// ActualizarResena modifica la calificación o los comentarios de una reseña
+// propia. Los campos nulos se mantienen.
+func (r *Resolver) ActualizarResena(ctx context.Context, reviewID string, rating *int, comments *string) (*models.Reseña, error) {
+	usuario, err := usuarioActual(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resena, err := r.Store.Repositorios().Resenas.PorID(ctx, reviewID)
+	if err != nil {
+		return nil, errors.New("reseña no encontrada")
+	}
+	if resena.UserID != usuario.UserID {
+		return nil, errProhibido()
+	}
+
+	if rating != nil {
+		if err := validarCalificacion(*rating); err != nil {
+			return nil, err
+		}
+		resena.Rating = *rating
+	}
+	if comments != nil {
+		resena.Comments = *comments
+	}
+	resena.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	if err := r.Store.Repositorios().Resenas.Editar(ctx, resena); err != nil {
+		return nil, errors.New("no se pudo actualizar la reseña")
+	}
+	return resena, nil
+}
+
+// EliminarResena borra una reseña. Puede hacerlo su autor o un administrador.
+func (r *Resolver) EliminarResena(ctx context.Context, reviewID string) (bool, error) {
+	resenas := r.Store.Repositorios().Resenas
+	resena, err := resenas.PorID(ctx, reviewID)
+	if err != nil {
+		return false, errors.New("reseña no encontrada")
+	}
+	if _, err := autorizarSobre(ctx, resena.UserID); err != nil {
+		return false, err
+	}
+
+	if err := resenas.Eliminar(ctx, resena.ReviewID); err != nil {
+		return false, errors.New("no se pudo eliminar la reseña")
+	}
+	return true, nil
+}
+
+// ResponderResena agrega la respuesta de un instructor a una reseña y avisa
+// a su autor. La directiva @hasRole exige el rol de instructor; además solo
+// el instructor del curso o un administrador pueden responder.
+func (r *Resolver) ResponderResena(ctx context.Context, reviewID string, reply string) (*models.Reseña, error) {
+	usuario, err := usuarioActual(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(reply) == "" {
+		return nil, errors.New("la respuesta no puede estar vacía")
+	}
+
+	resena, err := r.Store.Repositorios().Resenas.PorID(ctx, reviewID)
+	if err != nil {
+		return nil, errors.New("reseña no encontrada")
+	}
+
+	if !esAdmin(usuario) {
+		curso, err := r.Cursos.GetCourse(ctx, resena.CourseID)
+		if err != nil {
+			return nil, err
+		}
+		if curso.Instructor != usuario.UserID {
+			return nil, errProhibido()
+		}
+	}
+
+	resena.Reply = reply
+	resena.RepliedAt = time.Now().UTC().Format(time.RFC3339)
+	if err := r.Store.Repositorios().Resenas.Responder(ctx, resena); err != nil {
+		return nil, errors.New("no se pudo guardar la respuesta")
+	}
+
+	r.notificarSinFallar(ctx, resena.UserID, models.NotificacionResenaRespondida,
+		"Respondieron tu reseña del curso "+resena.CourseID+".")
+
+	return resena, nil
+}
+
+// ResenasPorCurso devuelve las reseñas de un curso, de la más reciente a la más antigua.
+func (r *Resolver) ResenasPorCurso(ctx context.Context, courseID string) ([]models.Reseña, error) {
+	resenas, err := r.Store.Repositorios().Resenas.DeCurso(ctx, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener las reseñas: %v", err)
+	}
+	return resenas, nil
+}
+
+// ResumenCalificaciones calcula el promedio, la cantidad y el histograma de
+// calificaciones de un curso. histogram[i] cuenta las reseñas con i+1 estrellas.
+func (r *Resolver) ResumenCalificaciones(ctx context.Context, courseID string) (*model.CourseRatingSummary, error) {
+	conteo, err := r.Store.Repositorios().Resenas.ContarPorCalificacion(ctx, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("error al calcular las calificaciones: %v", err)
+	}
+
+	resumen := &model.CourseRatingSummary{
+		CourseID:  courseID,
+		Histogram: make([]int, calificacionMaxima),
+	}
+	suma := 0
+	for rating, total := range conteo {
+		if rating < calificacionMinima || rating > calificacionMaxima {
+			continue
+		}
+		resumen.Histogram[rating-1] = total
+		resumen.Count += total
+		suma += rating * total
+	}
+	if resumen.Count > 0 {
+		resumen.Average = math.Round(float64(suma)/float64(resumen.Count)*100) / 100
+	}
+	return resumen, nil
+}
+
+func validarCalificacion(rating int) error {
+	if rating < calificacionMinima || rating > calificacionMaxima {
+		return fmt.Errorf("la calificación debe estar entre %d y %d", calificacionMinima, calificacionMaxima)
+	}
+	return nil
+}
+
+// resenaGraphQL convierte una reseña de la base de datos al tipo de GraphQL.
+func resenaGraphQL(resena *models.Reseña) *model.Resena {
+	return &model.Resena{
+		ReviewID:  resena.ReviewID,
+		UserID:    resena.UserID,
+		CourseID:  resena.CourseID,
+		Rating:    resena.Rating,
+		Comments:  resena.Comments,
+		CreatedAt: resena.CreatedAt,
+		UpdatedAt: resena.UpdatedAt,
+		Reply:     stringOpcional(resena.Reply),
+		RepliedAt: stringOpcional(resena.RepliedAt),
+	}
+}
+
+// stringOpcional devuelve nil para cadenas vacías, que en GraphQL se exponen como null.
+func stringOpcional(valor string) *string {
+	if valor == "" {
+		return nil
+	}
+	return &valor
+}
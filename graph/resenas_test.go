@@ -0,0 +1,44 @@
+package graph
+
+import (
+	"testing"
+
+	"ProyectoIngeso/courses"
+	"ProyectoIngeso/models"
+)
+
+func TestResponderResenaSoloElInstructorDelCurso(t *testing.T) {
+	paraCadaStore(t, func(t *testing.T, r *Resolver, catalogo *courses.FakeCatalog) {
+		ctxAna, ana := registrar(t, r, "ana")
+		ctxInstructor, instructor := registrar(t, r, "instructor")
+		ctxOtro, _ := registrar(t, r, "otro")
+
+		// Course.Instructor lleva el userID del instructor
+		catalogo.Agregar(courses.Course{ID: "c1", Price: 10, Instructor: instructor.UserID})
+		if _, err := r.Servicios.Inscripciones.Inscribir(ctxAna, ana.UserID, "c1", models.InscripcionAdmin); err != nil {
+			t.Fatal(err)
+		}
+		resena, err := r.CrearResena(ctxAna, "c1", 5, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := r.ResponderResena(ctxOtro, resena.ReviewID, "gracias"); err == nil || err.Error() != errProhibido().Error() {
+			t.Errorf("ResponderResena de otro instructor = %v", err)
+		}
+
+		respondida, err := r.ResponderResena(ctxInstructor, resena.ReviewID, "gracias")
+		if err != nil {
+			t.Fatalf("ResponderResena del instructor del curso = %v", err)
+		}
+		if respondida.Reply != "gracias" || respondida.RepliedAt == "" {
+			t.Errorf("reseña respondida = %+v", respondida)
+		}
+
+		// El autor recibe el aviso
+		notificaciones, err := r.Store.Repositorios().Notificaciones.Pagina(ctxAna, ana.UserID, models.NotificacionNoLeida, "", "", 10)
+		if err != nil || len(notificaciones) != 1 || notificaciones[0].Type != models.NotificacionResenaRespondida {
+			t.Errorf("notificaciones del autor = %+v, %v", notificaciones, err)
+		}
+	})
+}
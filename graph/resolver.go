@@ -1,23 +1,40 @@
 package graph
 
 import (
+	"ProyectoIngeso/courses"
+	"ProyectoIngeso/events"
 	"ProyectoIngeso/graph/model"
 	"ProyectoIngeso/models"
+	"ProyectoIngeso/payments"
+	"ProyectoIngeso/pubsub"
+	"ProyectoIngeso/repository"
+	"ProyectoIngeso/services"
 	"ProyectoIngeso/utils"
-	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
 	"time"
 
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 )
 
 type Resolver struct {
-	DB *gorm.DB
+	// Pagos, reseñas y notificaciones; el resto pasa por Servicios
+	Store  repository.Store
+	Pagos  payments.PaymentGateway
+	Cursos courses.Catalog
+	// Catálogo sin caché para cobrar el precio vigente; nil usa Cursos
+	Precios courses.Catalog
+
+	// Reglas de usuarios, carritos e inscripciones, compartidas con RabbitMQ
+	Servicios services.Servicios
+
+	// Eventos de dominio para los demás servicios; nil los desactiva
+	Eventos events.Publisher
+
+	// Brokers en memoria que alimentan las suscripciones, por userID
+	Notificaciones *pubsub.Broker[*model.Notificacion]
+	Carritos       *pubsub.Broker[*model.CartUpdate]
 }
 
 // RegistrarUsuario - maneja el registro de usuario
@@ -27,25 +44,15 @@ func (r *Resolver) RegistrarUsuario(ctx context.Context, input struct {
 	CorreoElectronico string
 	Contrasena        string
 }) (*models.Usuario, error) {
-	// 1. Cifrar la contraseña
-	hash, err := utils.HashContrasena(input.Contrasena)
+	// 1 y 2. Cifrar la contraseña y crear el usuario con el rol por defecto
+	usuario, err := r.Servicios.Usuarios.Registrar(ctx, services.NuevoUsuario{
+		NombreCompleto: input.NombreYapellido,
+		Username:       input.NombreUsuario,
+		Email:          input.CorreoElectronico,
+		Contrasena:     input.Contrasena,
+	})
 	if err != nil {
-		return nil, errors.New("error al cifrar la contraseña")
-	}
-
-	// 2. Crear el usuario
-	usuario := models.Usuario{
-		UserID:       generateUniqueID(), // Implementa esta función para generar un ID único
-		NameLastName: input.NombreYapellido,
-		Username:     input.NombreUsuario,
-		Email:        input.CorreoElectronico,
-		Password:     hash,
-		Role:         "user", // Rol por defecto
-	}
-
-	// Guardar el usuario en la base de datos
-	if err := r.DB.Create(&usuario).Error; err != nil {
-		return nil, errors.New("error al crear el usuario")
+		return nil, err
 	}
 
 	/*// 3. Crear el carrito asociado al usuario recién creado
@@ -60,524 +67,413 @@ func (r *Resolver) RegistrarUsuario(ctx context.Context, input struct {
 		return nil, errors.New("error al crear el carrito del usuario")
 	}*/
 
+	r.emitirEvento(ctx, events.UsuarioRegistradoV1{
+		UserID:       usuario.UserID,
+		Username:     usuario.Username,
+		Email:        usuario.Email,
+		NameLastName: usuario.NameLastName,
+	})
+
 	// 4. Retornar el usuario creado
-	return &usuario, nil
+	return usuario, nil
 }
 
 // IniciarSesion - maneja el inicio de sesión del usuario
 func (r *Resolver) IniciarSesion(ctx context.Context, input struct {
 	Identificador string
 	Contrasena    string
-}) (string, error) {
-	var usuario models.Usuario
-	if err := r.DB.Where("email = ? OR username = ?", input.Identificador, input.Identificador).First(&usuario).Error; err != nil {
-		return "", errors.New("usuario no encontrado")
+}) (*model.AuthPayload, error) {
+	usuario, err := r.Servicios.Usuarios.Autenticar(ctx, input.Identificador, input.Contrasena)
+	if err != nil {
+		return nil, err
 	}
 
-	if !utils.VerificarHashContrasena(input.Contrasena, usuario.Password) {
-		return "", errors.New("contraseña inválida")
+	return r.emitirTokens(*usuario)
+}
+
+// RefrescarToken - emite un nuevo par de tokens a partir de un token de refresco válido
+func (r *Resolver) RefrescarToken(ctx context.Context, refreshToken string) (*model.AuthPayload, error) {
+	claims, err := utils.ValidarToken(refreshToken, utils.TokenRefresco)
+	if err != nil {
+		return nil, err
+	}
+
+	// Se vuelve a leer el usuario para reflejar cambios de email o rol
+	usuario, err := r.Servicios.Usuarios.PorID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
 	}
 
-	return "Inicio de sesión exitoso", nil
+	return r.emitirTokens(*usuario)
 }
 
-// UpdateUsername - maneja la actualización del nombre de usuario
-func (r *Resolver) UpdateUsername(ctx context.Context, username string, newUsername string) (*models.Usuario, error) {
-	var usuario models.Usuario
+// emitirTokens firma los tokens del usuario y arma la respuesta GraphQL
+func (r *Resolver) emitirTokens(usuario models.Usuario) (*model.AuthPayload, error) {
+	token, refresh, expira, err := utils.GenerarTokens(usuario)
+	if err != nil {
+		return nil, errors.New("no se pudo generar el token de acceso")
+	}
+
+	return &model.AuthPayload{
+		Token:        token,
+		RefreshToken: refresh,
+		ExpiresAt:    expira.UTC().Format(time.RFC3339),
+		Usuario:      usuarioGraphQL(&usuario),
+	}, nil
+}
 
+// UpdateUsername - maneja la actualización del nombre de usuario
+func (r *Resolver) UpdateUsername(ctx context.Context, username *string, newUsername string) (*models.Usuario, error) {
 	// Buscar el usuario por el nombre de usuario actual
-	if err := r.DB.Where("username = ?", username).First(&usuario).Error; err != nil {
-		return nil, errors.New("usuario no encontrado")
+	usuario, err := r.usuarioObjetivo(ctx, r.Servicios.Usuarios.PorUsername, username)
+	if err != nil {
+		return nil, err
 	}
 
 	// Actualizar el nombre de usuario
-	usuario.Username = newUsername
-
-	// Guardar los cambios
-	if err := r.DB.Save(&usuario).Error; err != nil {
-		return nil, errors.New("no se pudo actualizar el nombre de usuario")
+	if err := r.Servicios.Usuarios.CambiarUsername(ctx, usuario, newUsername); err != nil {
+		return nil, err
 	}
 
 	// Retornar el usuario actualizado
-	return &usuario, nil
+	return usuario, nil
 }
 
 // UpdatePassword - maneja la actualización de la contraseña
-func (r *Resolver) UpdatePassword(ctx context.Context, username string, oldPassword string, newPassword string) (string, error) {
-	var usuario models.Usuario
-
+func (r *Resolver) UpdatePassword(ctx context.Context, username *string, oldPassword string, newPassword string) (string, error) {
 	// Buscar el usuario por el nombre de usuario
-	if err := r.DB.Where("username = ?", username).First(&usuario).Error; err != nil {
-		return "", errors.New("usuario no encontrado")
-	}
-
-	// Verificar que la contraseña actual sea correcta
-	if !utils.VerificarHashContrasena(oldPassword, usuario.Password) {
-		return "", errors.New("la contraseña actual es incorrecta")
-	}
-
-	// Cifrar la nueva contraseña
-	newHashedPassword, err := utils.HashContrasena(newPassword)
+	usuario, err := r.usuarioObjetivo(ctx, r.Servicios.Usuarios.PorUsername, username)
 	if err != nil {
-		return "", errors.New("error al cifrar la nueva contraseña")
+		return "", err
 	}
 
-	// Actualizar la contraseña
-	usuario.Password = newHashedPassword
+	return r.cambiarContrasena(ctx, usuario, oldPassword, newPassword)
+}
 
-	// Guardar los cambios
-	if err := r.DB.Save(&usuario).Error; err != nil {
-		return "", errors.New("no se pudo actualizar la contraseña")
+// cambiarContrasena aplica el cambio de contraseña común a UpdatePassword y
+// ActualizarContrasena y avisa al usuario.
+func (r *Resolver) cambiarContrasena(ctx context.Context, usuario *models.Usuario, oldPassword string, newPassword string) (string, error) {
+	if err := r.Servicios.Usuarios.CambiarContrasena(ctx, usuario, oldPassword, newPassword); err != nil {
+		return "", err
 	}
 
+	r.notificarSinFallar(ctx, usuario.UserID, models.NotificacionContrasenaCambiada,
+		"Tu contraseña fue cambiada. Si no fuiste tú, contacta a soporte.")
+
 	return "Contraseña actualizada exitosamente", nil
 }
 
-func (r *Resolver) ActualizarUsernameConEmail(ctx context.Context, email string, newUsername string) (*models.Usuario, error) {
-	var usuario models.Usuario
-
+func (r *Resolver) ActualizarUsernameConEmail(ctx context.Context, email *string, newUsername string) (*models.Usuario, error) {
 	// Buscar el usuario por su email
-	if err := r.DB.Where("email = ?", email).First(&usuario).Error; err != nil {
-		return nil, errors.New("usuario no encontrado")
-	}
-
-	// Verificar si el nuevo username ya está en uso
-	var existingUser models.Usuario
-	if err := r.DB.Where("username = ?", newUsername).First(&existingUser).Error; err == nil {
-		return nil, errors.New("el nombre de usuario ya está en uso")
+	usuario, err := r.usuarioObjetivo(ctx, r.Servicios.Usuarios.PorEmail, email)
+	if err != nil {
+		return nil, err
 	}
 
-	// Actualizar el username
-	usuario.Username = newUsername
-	if err := r.DB.Save(&usuario).Error; err != nil {
-		return nil, errors.New("no se pudo actualizar el nombre de usuario")
+	// Actualizar el username si no está en uso
+	if err := r.Servicios.Usuarios.CambiarUsername(ctx, usuario, newUsername); err != nil {
+		return nil, err
 	}
 
-	return &usuario, nil
+	return usuario, nil
 }
 
-func (r *Resolver) ActualizarNombreCompleto(ctx context.Context, email string, newNameLastName string) (*models.Usuario, error) {
-	var usuario models.Usuario
-
+func (r *Resolver) ActualizarNombreCompleto(ctx context.Context, email *string, newNameLastName string) (*models.Usuario, error) {
 	// Buscar el usuario por su email
-	if err := r.DB.Where("email = ?", email).First(&usuario).Error; err != nil {
-		return nil, errors.New("usuario no encontrado")
+	usuario, err := r.usuarioObjetivo(ctx, r.Servicios.Usuarios.PorEmail, email)
+	if err != nil {
+		return nil, err
 	}
 
 	// Actualizar el nombre completo
-	usuario.NameLastName = newNameLastName
-	if err := r.DB.Save(&usuario).Error; err != nil {
-		return nil, errors.New("no se pudo actualizar el nombre completo")
+	if err := r.Servicios.Usuarios.CambiarNombre(ctx, usuario, newNameLastName); err != nil {
+		return nil, err
 	}
 
-	return &usuario, nil
+	return usuario, nil
 }
 
-func (r *Resolver) ActualizarEmail(ctx context.Context, email string, newEmail string) (*models.Usuario, error) {
-	var usuario models.Usuario
-
+func (r *Resolver) ActualizarEmail(ctx context.Context, email *string, newEmail string) (*models.Usuario, error) {
 	// Buscar el usuario por su email actual
-	if err := r.DB.Where("email = ?", email).First(&usuario).Error; err != nil {
-		return nil, errors.New("usuario no encontrado")
+	usuario, err := r.usuarioObjetivo(ctx, r.Servicios.Usuarios.PorEmail, email)
+	if err != nil {
+		return nil, err
 	}
 
-	// Verificar si el nuevo email ya está en uso
-	var existingUser models.Usuario
-	if err := r.DB.Where("email = ?", newEmail).First(&existingUser).Error; err == nil {
-		return nil, errors.New("el email ya está en uso")
+	// Actualizar el email si no está en uso
+	anterior := usuario.Email
+	if err := r.Servicios.Usuarios.CambiarEmail(ctx, usuario, newEmail); err != nil {
+		return nil, err
 	}
+	r.emitirEvento(ctx, events.EmailCambiadoV1{UserID: usuario.UserID, PreviousEmail: anterior, Email: usuario.Email})
 
-	// Actualizar el email
-	usuario.Email = newEmail
-	if err := r.DB.Save(&usuario).Error; err != nil {
-		return nil, errors.New("no se pudo actualizar el email")
-	}
+	r.notificarSinFallar(ctx, usuario.UserID, models.NotificacionEmailCambiado,
+		fmt.Sprintf("Tu email fue cambiado a %s.", newEmail))
 
-	return &usuario, nil
+	return usuario, nil
 }
-func (r *Resolver) ActualizarContrasena(ctx context.Context, email string, oldPassword string, newPassword string) (string, error) {
-	var usuario models.Usuario
 
+func (r *Resolver) ActualizarContrasena(ctx context.Context, email *string, oldPassword string, newPassword string) (string, error) {
 	// Buscar el usuario por el email
-	if err := r.DB.Where("email = ?", email).First(&usuario).Error; err != nil {
-		return "", errors.New("usuario no encontrado")
-	}
-
-	// Verificar que la contraseña actual sea correcta
-	if !utils.VerificarHashContrasena(oldPassword, usuario.Password) {
-		return "", errors.New("la contraseña actual es incorrecta")
-	}
-
-	// Cifrar la nueva contraseña
-	newHashedPassword, err := utils.HashContrasena(newPassword)
+	usuario, err := r.usuarioObjetivo(ctx, r.Servicios.Usuarios.PorEmail, email)
 	if err != nil {
-		return "", errors.New("error al cifrar la nueva contraseña")
-	}
-
-	// Actualizar la contraseña
-	usuario.Password = newHashedPassword
-
-	// Guardar los cambios
-	if err := r.DB.Save(&usuario).Error; err != nil {
-		return "", errors.New("no se pudo actualizar la contraseña")
+		return "", err
 	}
 
-	return "Contraseña actualizada exitosamente", nil
+	return r.cambiarContrasena(ctx, usuario, oldPassword, newPassword)
 }
 
 // DeleteUserByUsername - elimina un usuario por su nombre de usuario
-func (r *Resolver) DeleteUserByUsername(ctx context.Context, username string) (string, error) {
-	var usuario models.Usuario
-
+func (r *Resolver) DeleteUserByUsername(ctx context.Context, username *string) (string, error) {
 	// Buscar el usuario por el nombre de usuario
-	if err := r.DB.Where("username = ?", username).First(&usuario).Error; err != nil {
-		return "", errors.New("usuario no encontrado")
+	usuario, err := r.usuarioObjetivo(ctx, r.Servicios.Usuarios.PorUsername, username)
+	if err != nil {
+		return "", err
 	}
 
 	// Eliminar el usuario de la base de datos
-	if err := r.DB.Delete(&usuario).Error; err != nil {
-		return "", errors.New("no se pudo eliminar el usuario")
+	if err := r.Servicios.Usuarios.Eliminar(ctx, usuario); err != nil {
+		return "", err
 	}
+	r.emitirEvento(ctx, events.UsuarioEliminadoV1{UserID: usuario.UserID, Email: usuario.Email})
 
 	return "Usuario eliminado exitosamente", nil
 }
 
-// AddToCart agrega un curso al carrito del usuario.
-func (r *Resolver) AddToCart(ctx context.Context, username string, courseID string) (*model.Carrito, error) {
-	// Verificar si el usuario existe y obtener el userID.
-	userID, err := r.checkUserExists(username)
+// ActualizarRol promueve o degrada a un usuario. La restricción a
+// administradores la aplica la directiva @hasRole.
+func (r *Resolver) ActualizarRol(ctx context.Context, username string, rol string) (*models.Usuario, error) {
+	actual, err := usuarioActual(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error al verificar el usuario: %v", err)
+		return nil, err
 	}
 
-	// Verificar si el curso existe en el servicio de cursos.
-	courseExists, err := r.checkCourseExists(courseID)
-	if err != nil {
-		return nil, fmt.Errorf("error al verificar el curso: %v", err)
-	}
-	if !courseExists {
-		return nil, fmt.Errorf("curso con ID %s no encontrado", courseID)
+	if _, ok := nivelRol[rol]; !ok {
+		return nil, fmt.Errorf("rol %s no válido", rol)
 	}
 
-	// Crear un nuevo elemento en el carrito.
-	cartItem := &model.Carrito{
-		CartID:   uuid.New().String(),
-		UserID:   userID, // Asegúrate de que esta variable no esté vacía.
-		CourseID: courseID,
+	usuario, err := r.Servicios.Usuarios.PorUsername(ctx, username)
+	if err != nil {
+		return nil, err
 	}
 
-	// Verificar si el campo UserID no está vacío.
-	if cartItem.UserID == "" {
-		return nil, fmt.Errorf("userID no puede estar vacío")
+	// Evita que un administrador se quite sus propios permisos por error
+	if usuario.UserID == actual.UserID {
+		return nil, errors.New("no puedes cambiar tu propio rol")
 	}
 
-	// Guardar el elemento en la base de datos.
-	if err := r.DB.Create(cartItem).Error; err != nil {
+	if err := r.Servicios.Usuarios.CambiarRol(ctx, usuario, rol); err != nil {
 		return nil, err
 	}
 
-	return cartItem, nil
+	return usuario, nil
 }
 
-// AddToCartByEmail agrega un curso al carrito del usuario utilizando el correo electrónico.
-func (r *Resolver) AddToCartbyEmail(ctx context.Context, email string, courseID string) (*model.Carrito, error) {
-	// Verificar si el usuario existe y obtener el userID mediante el email.
-	userID, err := r.checkUserExistsByEmail(email)
-	if err != nil {
-		return nil, fmt.Errorf("error al verificar el usuario: %v", err)
-	}
-
-	// Verificar si el curso existe en el servicio de cursos.
-	courseExists, err := r.checkCourseExists(courseID)
+// AddToCart agrega un curso al carrito del usuario.
+func (r *Resolver) AddToCart(ctx context.Context, username *string, courseID string) (*model.Carrito, error) {
+	// Verificar si el usuario existe y obtener el userID.
+	usuario, err := r.usuarioObjetivo(ctx, r.Servicios.Usuarios.PorUsername, username)
 	if err != nil {
-		return nil, fmt.Errorf("error al verificar el curso: %v", err)
-	}
-	if !courseExists {
-		return nil, fmt.Errorf("curso con ID %s no encontrado", courseID)
+		return nil, err
 	}
+	userID := usuario.UserID
 
-	// Obtener los cursos del usuario mediante la consulta GetCoursesByEmail
-	userCourses, err := r.GetCoursesByEmail(ctx, email)
+	// Agregar el curso si existe y el usuario no lo tiene ni está ya en su carrito.
+	cartItem, err := r.Servicios.Carritos.Agregar(ctx, userID, courseID)
 	if err != nil {
-		return nil, fmt.Errorf("error al obtener los cursos del usuario: %v", err)
-	}
-
-	// Verificar si el curso ya está en la lista de cursos del usuario
-	for _, usuarioCurso := range userCourses {
-		if usuarioCurso.CourseID == courseID {
-			return nil, fmt.Errorf("el usuario ya tiene este curso en su lista de cursos")
-		}
-	}
-
-	// Verificar si el curso ya está en el carrito del usuario
-	existingCartItem := &model.Carrito{}
-	err = r.DB.Where("user_id = ? AND course_id = ?", userID, courseID).First(&existingCartItem).Error
-	if err == nil {
-		// Si se encuentra un item en el carrito, no lo agregamos de nuevo.
-		return nil, fmt.Errorf("el curso ya está en tu carrito")
+		return nil, err
 	}
 
-	// Crear un nuevo elemento en el carrito.
-	cartItem := &model.Carrito{
-		CartID:   uuid.New().String(),
-		UserID:   userID,
-		CourseID: courseID,
-	}
+	r.publicarCarrito(userID, AccionCarritoAgregado, courseID)
+	r.emitirEvento(ctx, events.ItemCarritoAgregadoV1{UserID: userID, CartID: cartItem.CartID, CourseID: courseID})
+	return carritoGraphQL(cartItem), nil
+}
 
-	// Verificar si el campo UserID no está vacío.
-	if cartItem.UserID == "" {
-		return nil, fmt.Errorf("userID no puede estar vacío")
+// AddToCartByEmail agrega un curso al carrito del usuario utilizando el correo electrónico.
+func (r *Resolver) AddToCartbyEmail(ctx context.Context, email *string, courseID string) (*model.Carrito, error) {
+	// Verificar si el usuario existe y obtener el userID mediante el email.
+	usuario, err := r.usuarioObjetivo(ctx, r.Servicios.Usuarios.PorEmail, email)
+	if err != nil {
+		return nil, err
 	}
+	userID := usuario.UserID
 
-	// Guardar el elemento en la base de datos.
-	if err := r.DB.Create(cartItem).Error; err != nil {
+	// Agregar el curso si existe y el usuario no lo tiene ni está ya en su carrito.
+	cartItem, err := r.Servicios.Carritos.Agregar(ctx, userID, courseID)
+	if err != nil {
 		return nil, err
 	}
 
-	return cartItem, nil
+	r.publicarCarrito(userID, AccionCarritoAgregado, courseID)
+	r.emitirEvento(ctx, events.ItemCarritoAgregadoV1{UserID: userID, CartID: cartItem.CartID, CourseID: courseID})
+	return carritoGraphQL(cartItem), nil
 }
 
 // DeleteCartByID elimina un carrito por su ID
 func (r *Resolver) DeleteCartByID(ctx context.Context, cartID string) (string, error) {
 	// Buscar el carrito por su ID
-	var carrito model.Carrito
-	if err := r.DB.Where("cart_id = ?", cartID).First(&carrito).Error; err != nil {
-		return "", errors.New("carrito no encontrado")
+	carrito, err := r.Servicios.Carritos.Item(ctx, cartID)
+	if err != nil {
+		return "", err
+	}
+
+	// Solo el dueño del carrito o un administrador puede eliminarlo
+	if _, err := autorizarSobre(ctx, carrito.UserID); err != nil {
+		return "", err
 	}
 
 	// Eliminar el carrito de la base de datos
-	if err := r.DB.Delete(&carrito).Error; err != nil {
-		return "", errors.New("no se pudo eliminar el carrito")
+	if err := r.Servicios.Carritos.EliminarItem(ctx, carrito.CartID); err != nil {
+		return "", err
 	}
 
+	r.publicarCarrito(carrito.UserID, AccionCarritoQuitado, carrito.CourseID)
+
 	return "Carrito eliminado exitosamente", nil
 }
 
 // DeleteCartByCourseID elimina el carrito de un usuario por courseID.
 func (r *Resolver) DeleteCartByCourseID(ctx context.Context, courseID string) (string, error) {
-	// Verificar si el curso existe en el servicio de cursos.
-	courseExists, err := r.checkCourseExists(courseID)
+	// Quitar el curso de todos los carritos, recordando a quién avisar.
+	userIDs, err := r.Servicios.Carritos.QuitarCursoDeTodos(ctx, courseID)
 	if err != nil {
-		return "", fmt.Errorf("error al verificar el curso: %v", err)
-	}
-	if !courseExists {
-		return "", fmt.Errorf("curso con ID %s no encontrado", courseID)
+		return "", err
 	}
 
-	// Eliminar todos los registros de carrito con el courseID especificado.
-	if err := r.DB.Where("course_id = ?", courseID).Delete(&model.Carrito{}).Error; err != nil {
-		return "", errors.New("no se pudo eliminar los carritos con el curso especificado")
+	for _, userID := range userIDs {
+		r.publicarCarrito(userID, AccionCarritoQuitado, courseID)
 	}
 
 	return "Carritos eliminados exitosamente", nil
 }
 
 // RemoveFromCart elimina un curso del carrito del usuario.
-func (r *Resolver) RemoveFromCart(ctx context.Context, username string, courseID string) (*bool, error) {
+func (r *Resolver) RemoveFromCart(ctx context.Context, username *string, courseID string) (*bool, error) {
 	// Verificar si el usuario existe y obtener su userID.
-	userID, err := r.checkUserExists(username)
+	usuario, err := r.usuarioObjetivo(ctx, r.Servicios.Usuarios.PorUsername, username)
 	if err != nil {
-		return nil, fmt.Errorf("error al verificar el usuario: %v", err)
-	}
-
-	// Verificar si el curso existe en el servicio de cursos.
-	courseExists, err := r.checkCourseExists(courseID)
-	if err != nil {
-		return nil, fmt.Errorf("error al verificar el curso: %v", err)
-	}
-	if !courseExists {
-		return nil, fmt.Errorf("curso con ID %s no encontrado", courseID)
+		return nil, err
 	}
+	userID := usuario.UserID
 
-	// Eliminar el curso del carrito del usuario usando el userID.
-	if err := r.DB.Where("userID = ? AND courseID = ?", userID, courseID).Delete(&model.Carrito{}).Error; err != nil {
+	// Eliminar el curso del carrito del usuario, si el curso existe.
+	if err := r.Servicios.Carritos.Quitar(ctx, userID, courseID); err != nil {
 		return nil, err
 	}
 
+	r.publicarCarrito(userID, AccionCarritoQuitado, courseID)
+
 	success := true
 	return &success, nil
 }
 
 // ViewCartByUserID permite ver el carrito del usuario utilizando el userID.
-func (r *Resolver) ViewCartByUserID(ctx context.Context, userID string) ([]*model.Carrito, error) {
-	var carrito []*model.Carrito
-
-	// Buscar todos los elementos del carrito asociados al userID.
-	if err := r.DB.Where("user_id = ?", userID).Find(&carrito).Error; err != nil {
-		return nil, fmt.Errorf("error al obtener el carrito: %v", err)
+func (r *Resolver) ViewCartByUserID(ctx context.Context, userID *string) ([]*model.Carrito, error) {
+	usuario, err := r.usuarioObjetivo(ctx, r.Servicios.Usuarios.PorID, userID)
+	if err != nil {
+		return nil, err
 	}
 
-	return carrito, nil
+	return r.itemsCarrito(ctx, usuario.UserID)
 }
 
 // ViewCartByUsername permite ver el carrito del usuario utilizando el nombre de usuario.
-func (r *Resolver) ViewCartByUsername(ctx context.Context, username string) ([]*model.Carrito, error) {
+func (r *Resolver) ViewCartByUsername(ctx context.Context, username *string) ([]*model.Carrito, error) {
 	// Verificar si el usuario existe y obtener el userID.
-	userID, err := r.checkUserExists(username)
+	usuario, err := r.usuarioObjetivo(ctx, r.Servicios.Usuarios.PorUsername, username)
 	if err != nil {
-		return nil, fmt.Errorf("error al verificar el usuario: %v", err)
-	}
-
-	// Buscar todos los elementos del carrito asociados al userID.
-	var carrito []*model.Carrito
-	if err := r.DB.Where("user_id = ?", userID).Find(&carrito).Error; err != nil {
-		return nil, fmt.Errorf("error al obtener el carrito: %v", err)
+		return nil, err
 	}
 
-	return carrito, nil
+	return r.itemsCarrito(ctx, usuario.UserID)
 }
 
 // ViewCartByEmail permite ver el carrito del usuario utilizando el email.
-func (r *Resolver) ViewCartByEmail(ctx context.Context, email string) ([]*model.Carrito, error) {
+func (r *Resolver) ViewCartByEmail(ctx context.Context, email *string) ([]*model.Carrito, error) {
 	// Buscar el usuario por su email y obtener el userID.
-	var usuario model.Usuario
-	if err := r.DB.Where("email = ?", email).First(&usuario).Error; err != nil {
-		return nil, fmt.Errorf("usuario no encontrado")
-	}
-
-	// Buscar todos los elementos del carrito asociados al userID.
-	var carrito []*model.Carrito
-	if err := r.DB.Where("user_id = ?", usuario.UserID).Find(&carrito).Error; err != nil {
-		return nil, fmt.Errorf("error al obtener el carrito: %v", err)
+	usuario, err := r.usuarioObjetivo(ctx, r.Servicios.Usuarios.PorEmail, email)
+	if err != nil {
+		return nil, err
 	}
 
-	return carrito, nil
+	return r.itemsCarrito(ctx, usuario.UserID)
 }
 
-// AddCourseToUser agrega un curso a la lista de cursos de un usuario por su ID.
-func (r *Resolver) AddCourseToUser(ctx context.Context, email string, courseID string) (string, error) {
-	// Verificar si el curso existe usando la función `checkCourseExists`.
-	exists, err := r.checkCourseExists(courseID)
-	if err != nil {
-		return "", fmt.Errorf("error al verificar la existencia del curso: %v", err)
-	}
-	if !exists {
-		return "", fmt.Errorf("el curso con ID %s no existe", courseID)
-	}
-
+// AddCourseToUser inscribe a un usuario en un curso sin pasar por el pago. La
+// restricción a administradores la aplica la directiva @hasRole.
+func (r *Resolver) AddCourseToUser(ctx context.Context, email *string, courseID string) (string, error) {
 	// Verificar si el usuario existe usando el email.
-	var usuario model.Usuario
-	if err := r.DB.Where("email = ?", email).First(&usuario).Error; err != nil {
-		return "", fmt.Errorf("usuario no encontrado: %v", err)
-	}
-
-	// Verificar si la relación usuario-curso ya existe.
-	var relacion model.UsuarioCurso
-	if err := r.DB.Where("email = ? AND course_id = ?", email, courseID).First(&relacion).Error; err == nil {
-		return "", fmt.Errorf("el usuario ya tiene este curso agregado")
+	usuario, err := r.usuarioObjetivo(ctx, r.Servicios.Usuarios.PorEmail, email)
+	if err != nil {
+		return "", err
 	}
 
-	// Crear la nueva relación usuario-curso.
-	nuevaRelacion := model.UsuarioCurso{
-		ID:       generateUniqueID(),
-		Email:    email, // Puedes optar por guardar el `username` del usuario si es necesario.
-		CourseID: courseID,
-	}
-	if err := r.DB.Create(&nuevaRelacion).Error; err != nil {
-		return "", fmt.Errorf("error al agregar el curso al usuario: %v", err)
+	// Inscribir al usuario si el curso existe y no lo tiene.
+	inscripcion, err := r.Servicios.Inscripciones.Inscribir(ctx, usuario.UserID, courseID, models.InscripcionAdmin)
+	if err != nil {
+		return "", err
 	}
+	r.emitirInscripcion(ctx, inscripcion)
 
 	return "Curso agregado exitosamente al usuario", nil
 }
 
 // GetCoursesByEmail obtiene los cursos asociados a un usuario dado su email.
-func (r *Resolver) GetCoursesByEmail(ctx context.Context, email string) ([]model.UsuarioCurso, error) {
-	// Verificar si existen cursos asociados al email proporcionado.
-	var cursos []model.UsuarioCurso
-	if err := r.DB.Where("email = ?", email).Find(&cursos).Error; err != nil {
+// Solo el propio usuario o un administrador pueden consultarlos.
+func (r *Resolver) GetCoursesByEmail(ctx context.Context, email string) ([]*model.UsuarioCurso, error) {
+	usuario, err := r.usuarioObjetivo(ctx, r.Servicios.Usuarios.PorEmail, &email)
+	if err != nil {
+		return nil, err
+	}
+
+	inscripciones, err := r.Servicios.Inscripciones.DeUsuario(ctx, usuario.UserID)
+	if err != nil {
 		return nil, fmt.Errorf("error al obtener los cursos para el email %s: %v", email, err)
 	}
 
+	cursos := make([]*model.UsuarioCurso, 0, len(inscripciones))
+	for i := range inscripciones {
+		cursos = append(cursos, inscripcionGraphQL(&inscripciones[i], email))
+	}
 	return cursos, nil
 }
 
+// ObtenerUsernamePorEmail devuelve el nombre de usuario asociado a un email.
+// Solo el propio usuario o un administrador pueden consultarlo.
 func (r *Resolver) ObtenerUsernamePorEmail(ctx context.Context, email string) (string, error) {
-    var usuario models.Usuario
-
-    // Buscar el usuario por el email
-    if err := r.DB.Where("email = ?", email).First(&usuario).Error; err != nil {
-        return "", errors.New("usuario no encontrado")
-    }
-
-    // Devolver el nombre de usuario
-    return usuario.Username, nil
+	usuario, err := r.usuarioObjetivo(ctx, r.Servicios.Usuarios.PorEmail, &email)
+	if err != nil {
+		return "", err
+	}
+	return usuario.Username, nil
 }
 
-
 // GetAllUsers devuelve todos los usuarios.
 func (r *Resolver) GetAllUsers(ctx context.Context) ([]*model.Usuario, error) {
-	var users []*model.Usuario
-
 	// Consultar todos los usuarios en la base de datos.
-	if err := r.DB.Find(&users).Error; err != nil {
-		return nil, fmt.Errorf("error al obtener los usuarios: %v", err)
-	}
-
-	return users, nil
-}
-
-// checkUserExists verifica si un usuario existe en la base de datos y devuelve su userID.
-func (r *Resolver) checkUserExists(username string) (string, error) {
-	var usuario model.Usuario
-	if err := r.DB.Where("username = ?", username).First(&usuario).Error; err != nil {
-		return "", fmt.Errorf("usuario no encontrado")
-	}
-	return usuario.UserID, nil
-}
-func (r *Resolver) checkUserExistsByEmail(email string) (string, error) {
-	var usuario model.Usuario
-	if err := r.DB.Where("email = ?", email).First(&usuario).Error; err != nil {
-		return "", err
-	}
-	return usuario.UserID, nil
-}
-
-// checkCourseExists verifica si un curso existe en el servicio de cursos.
-func (r *Resolver) checkCourseExists(courseID string) (bool, error) {
-	url := "http://proyectoingesocursos:8081/graphql" // Asegúrate de que esta URL sea correcta.
-	query := fmt.Sprintf(`{"query": "query { cursoByID(courseID: \"%s\") { courseID } }"}`, courseID)
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer([]byte(query)))
-	if err != nil {
-		return false, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Do(req)
+	usuarios, err := r.Servicios.Usuarios.Todos(ctx)
 	if err != nil {
-		return false, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("error al verificar el curso, código de respuesta: %d", resp.StatusCode)
-	}
-
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return false, err
+		return nil, fmt.Errorf("error al obtener los usuarios: %v", err)
 	}
 
-	// Verificar si la respuesta contiene el curso.
-	if data, found := result["data"].(map[string]interface{}); found {
-		if course, exists := data["cursoByID"].(map[string]interface{}); exists && course["courseID"] != nil {
-			return true, nil
-		}
+	users := make([]*model.Usuario, 0, len(usuarios))
+	for i := range usuarios {
+		users = append(users, usuarioGraphQL(&usuarios[i]))
 	}
-
-	return false, nil
+	return users, nil
 }
 
-func (r *queryResolver) UsuarioByUsername(ctx context.Context, username string) (*model.Usuario, error) {
-	var usuario model.Usuario
-
-	// Buscar el usuario por nombre de usuario en la base de datos
-	if err := r.DB.Where("username = ?", username).First(&usuario).Error; err != nil {
-		return nil, fmt.Errorf("usuario no encontrado: %v", err)
+// usuarioGraphQL convierte el modelo de base de datos al tipo público de
+// GraphQL. Los campos se copian uno a uno a propósito: un campo nuevo en
+// models.Usuario (como Password) nunca se expone sin agregarlo aquí.
+func usuarioGraphQL(usuario *models.Usuario) *model.Usuario {
+	return &model.Usuario{
+		UserID:       usuario.UserID,
+		NameLastName: usuario.NameLastName,
+		Username:     usuario.Username,
+		Email:        usuario.Email,
+		Role:         usuario.Role,
 	}
-
-	return &usuario, nil
 }
 
 func generateUniqueID() string {
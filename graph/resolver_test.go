@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"ProyectoIngeso/courses"
+	"ProyectoIngeso/database/dbtest"
+	"ProyectoIngeso/migrations"
+	"ProyectoIngeso/models"
+	"ProyectoIngeso/payments"
+	"ProyectoIngeso/repository"
+	"ProyectoIngeso/services"
+	"ProyectoIngeso/utils"
+
+	"gorm.io/gorm"
+)
+
+// paraCadaStore corre prueba con un Resolver sobre FakeStore y sobre
+// GormStore en cada motor, con la pasarela falsa y un catálogo que empieza
+// con los cursos c1, c2 y c3.
+func paraCadaStore(t *testing.T, prueba func(t *testing.T, r *Resolver, catalogo *courses.FakeCatalog)) {
+	correr := func(t *testing.T, store repository.Store) {
+		catalogo := courses.NewFakeCatalog(
+			courses.Course{ID: "c1", Price: 10},
+			courses.Course{ID: "c2", Price: 20},
+			courses.Course{ID: "c3", Price: 30},
+		)
+		prueba(t, &Resolver{
+			Store:     store,
+			Pagos:     payments.NewFakeGateway("secreto"),
+			Cursos:    catalogo,
+			Servicios: services.New(store, catalogo),
+		}, catalogo)
+	}
+
+	t.Run("fake", func(t *testing.T) {
+		correr(t, repository.NewFakeStore())
+	})
+	dbtest.ParaCadaMotor(t, func(t *testing.T, db *gorm.DB) {
+		if _, err := migrations.Subir(db); err != nil {
+			t.Fatal(err)
+		}
+		correr(t, repository.NewGormStore(db))
+	})
+}
+
+// registrar crea un usuario y devuelve un contexto autenticado como él.
+func registrar(t *testing.T, r *Resolver, username string) (context.Context, *models.Usuario) {
+	t.Helper()
+	usuario, err := r.Servicios.Usuarios.Registrar(context.Background(), services.NuevoUsuario{
+		NombreCompleto: username,
+		Username:       username,
+		Email:          username + "@ejemplo.com",
+		Contrasena:     "secreta",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return utils.ContextoConUsuario(context.Background(), usuario), usuario
+}
@@ -6,221 +6,112 @@ package graph
 
 import (
 	"ProyectoIngeso/graph/model"
-	"ProyectoIngeso/models"
-	"ProyectoIngeso/utils"
 	"context"
 	"errors"
-	"fmt"
 )
 
+// Course is the resolver for the course field.
+func (r *carritoResolver) Course(ctx context.Context, obj *model.Carrito) (*model.Course, error) {
+	return r.Resolver.CursoDeItem(ctx, obj)
+}
+
+// Subtotal is the resolver for the subtotal field.
+func (r *cartResolver) Subtotal(ctx context.Context, obj *model.Cart) (float64, error) {
+	return r.Resolver.SubtotalCarrito(ctx, obj.UserID)
+}
+
 // RegisterUsuario maneja la mutación para registrar un usuario.
 func (r *mutationResolver) RegisterUsuario(ctx context.Context, nameLastName string, username string, email string, password string) (*model.Usuario, error) {
-	// 1. Hash de la contraseña
-	hash, err := utils.HashContrasena(password)
+	usuario, err := r.Resolver.RegistrarUsuario(ctx, struct {
+		NombreYapellido   string
+		NombreUsuario     string
+		CorreoElectronico string
+		Contrasena        string
+	}{NombreYapellido: nameLastName, NombreUsuario: username, CorreoElectronico: email, Contrasena: password})
 	if err != nil {
-		return nil, errors.New("error al cifrar la contraseña")
-	}
-
-	// 2. Crear el modelo de usuario en la base de datos
-	usuario := &models.Usuario{
-		UserID:       generateUniqueID(), // Implementa esta función para generar un ID único
-		NameLastName: nameLastName,
-		Username:     username,
-		Email:        email,
-		Password:     hash,
-		Role:         "user", // Asigna un rol por defecto
-	}
-
-	if err := r.DB.Create(usuario).Error; err != nil {
-		return nil, errors.New("no se pudo registrar el usuario")
-	}
-
-	/*// 3. Crear el carrito asociado al usuario
-	carrito := &models.Carrito{
-		CartID:   generateUniqueID(), // Genera un ID único para el carrito
-		UserID:   usuario.UserID,     // Relacionar el carrito con el usuario creado
-		CourseID: "",                 // Inicialmente sin curso asignado
+		return nil, err
 	}
 
-	if err := r.DB.Create(carrito).Error; err != nil {
-		return nil, errors.New("no se pudo crear el carrito del usuario")
-	}*/
-
-	// 4. Convertir el modelo de usuario a modelo GraphQL
-	return &model.Usuario{
-		UserID:       usuario.UserID,
-		NameLastName: usuario.NameLastName,
-		Username:     usuario.Username,
-		Email:        usuario.Email,
-		Password:     usuario.Password,
-		Role:         usuario.Role,
-	}, nil
+	// Convertir el modelo de usuario a modelo GraphQL
+	return usuarioGraphQL(usuario), nil
 }
 
 // LoginUsuario maneja la mutación para iniciar sesión.
-func (r *mutationResolver) LoginUsuario(ctx context.Context, identificador string, password string) (*string, error) {
-	var usuario models.Usuario
-	if err := r.DB.Where("email = ? OR username = ?", identificador, identificador).First(&usuario).Error; err != nil {
-		msg := "usuario no encontrado"
-		return &msg, nil
-	}
-
-	if !utils.VerificarHashContrasena(password, usuario.Password) {
-		msg := "contraseña inválida"
-		return &msg, nil
-	}
+func (r *mutationResolver) LoginUsuario(ctx context.Context, identificador string, password string) (*model.AuthPayload, error) {
+	return r.Resolver.IniciarSesion(ctx, struct {
+		Identificador string
+		Contrasena    string
+	}{Identificador: identificador, Contrasena: password})
+}
 
-	successMsg := "Inicio de sesión exitoso"
-	return &successMsg, nil
+// RefreshToken is the resolver for the refreshToken field.
+func (r *mutationResolver) RefreshToken(ctx context.Context, refreshToken string) (*model.AuthPayload, error) {
+	return r.Resolver.RefrescarToken(ctx, refreshToken)
 }
 
 // UpdateUsername maneja la mutación para actualizar el nombre de usuario.
-func (r *mutationResolver) ActualizarUsername(ctx context.Context, username string, newUsername string) (*model.Usuario, error) {
-	var usuario models.Usuario
-
-	// Buscar el usuario por el nombre de usuario actual
-	if err := r.DB.Where("username = ?", username).First(&usuario).Error; err != nil {
-		return nil, errors.New("usuario no encontrado")
-	}
-
-	// Actualizar el nombre de usuario
-	usuario.Username = newUsername
-
-	// Guardar los cambios
-	if err := r.DB.Save(&usuario).Error; err != nil {
-		return nil, errors.New("no se pudo actualizar el nombre de usuario")
+func (r *mutationResolver) ActualizarUsername(ctx context.Context, username *string, newUsername string) (*model.Usuario, error) {
+	usuario, err := r.Resolver.UpdateUsername(ctx, username, newUsername)
+	if err != nil {
+		return nil, err
 	}
 
 	// Retornar el usuario actualizado
-	return &model.Usuario{
-		UserID:       usuario.UserID,
-		NameLastName: usuario.NameLastName,
-		Username:     usuario.Username,
-		Email:        usuario.Email,
-		Password:     usuario.Password,
-		Role:         usuario.Role,
-	}, nil
+	return usuarioGraphQL(usuario), nil
 }
 
 // UpdatePassword maneja la mutación para actualizar la contraseña.
-func (r *mutationResolver) ActualizarPassword(ctx context.Context, username string, oldPassword string, newPassword string) (*string, error) {
-	var usuario models.Usuario
-
-	// Buscar el usuario por el nombre de usuario
-	if err := r.DB.Where("username = ?", username).First(&usuario).Error; err != nil {
-		return nil, errors.New("usuario no encontrado")
-	}
-
-	// Verificar que la contraseña actual sea correcta
-	if !utils.VerificarHashContrasena(oldPassword, usuario.Password) {
-		return nil, errors.New("la contraseña actual es incorrecta")
-	}
-
-	// Cifrar la nueva contraseña
-	newHashedPassword, err := utils.HashContrasena(newPassword)
+func (r *mutationResolver) ActualizarPassword(ctx context.Context, username *string, oldPassword string, newPassword string) (*string, error) {
+	successMsg, err := r.Resolver.UpdatePassword(ctx, username, oldPassword, newPassword)
 	if err != nil {
-		return nil, errors.New("error al cifrar la nueva contraseña")
-	}
-
-	// Actualizar la contraseña
-	usuario.Password = newHashedPassword
-
-	// Guardar los cambios
-	if err := r.DB.Save(&usuario).Error; err != nil {
-		return nil, errors.New("no se pudo actualizar la contraseña")
+		return nil, err
 	}
-
-	successMsg := "Contraseña actualizada exitosamente"
 	return &successMsg, nil
 }
 
 // ActualizarUsernameConEmail is the resolver for the actualizarUsernameConEmail field.
-func (r *mutationResolver) ActualizarUsernameConEmail(ctx context.Context, email string, newUsername string) (*model.Usuario, error) {
+func (r *mutationResolver) ActualizarUsernameConEmail(ctx context.Context, email *string, newUsername string) (*model.Usuario, error) {
 	usuario, err := r.Resolver.ActualizarUsernameConEmail(ctx, email, newUsername)
 	if err != nil {
 		return nil, err
 	}
-	return &model.Usuario{
-		UserID:       usuario.UserID,
-		NameLastName: usuario.NameLastName,
-		Username:     usuario.Username,
-		Email:        usuario.Email,
-		Password:     usuario.Password,
-		Role:         usuario.Role,
-	}, nil
+	return usuarioGraphQL(usuario), nil
 }
 
 // ActualizarNombreCompleto is the resolver for the actualizarNombreCompleto field.
-func (r *mutationResolver) ActualizarNombreCompleto(ctx context.Context, email string, newNameLastName string) (*model.Usuario, error) {
+func (r *mutationResolver) ActualizarNombreCompleto(ctx context.Context, email *string, newNameLastName string) (*model.Usuario, error) {
 	usuario, err := r.Resolver.ActualizarNombreCompleto(ctx, email, newNameLastName)
 	if err != nil {
 		return nil, err
 	}
-	return &model.Usuario{
-		UserID:       usuario.UserID,
-		NameLastName: usuario.NameLastName,
-		Username:     usuario.Username,
-		Email:        usuario.Email,
-		Password:     usuario.Password,
-		Role:         usuario.Role,
-	}, nil
+	return usuarioGraphQL(usuario), nil
 }
 
 // ActualizarEmail is the resolver for the actualizarEmail field.
-func (r *mutationResolver) ActualizarEmail(ctx context.Context, email string, newEmail string) (*model.Usuario, error) {
+func (r *mutationResolver) ActualizarEmail(ctx context.Context, email *string, newEmail string) (*model.Usuario, error) {
 	usuario, err := r.Resolver.ActualizarEmail(ctx, email, newEmail)
 	if err != nil {
 		return nil, err
 	}
-	return &model.Usuario{
-		UserID:       usuario.UserID,
-		NameLastName: usuario.NameLastName,
-		Username:     usuario.Username,
-		Email:        usuario.Email,
-		Password:     usuario.Password,
-		Role:         usuario.Role,
-	}, nil
+	return usuarioGraphQL(usuario), nil
 }
 
 // ActualizarContrasena is the resolver for the actualizarContrasena field.
-func (r *mutationResolver) ActualizarContrasena(ctx context.Context, email string, oldPassword string, newPassword string) (*string, error) {
-	var usuario models.Usuario
-
-	// Buscar el usuario por el nombre de usuario
-	if err := r.DB.Where("email = ?", email).First(&usuario).Error; err != nil {
-		return nil, errors.New("usuario no encontrado")
-	}
-
-	// Verificar que la contraseña actual sea correcta
-	if !utils.VerificarHashContrasena(oldPassword, usuario.Password) {
-		return nil, errors.New("la contraseña actual es incorrecta")
-	}
-
-	// Cifrar la nueva contraseña
-	newHashedPassword, err := utils.HashContrasena(newPassword)
+func (r *mutationResolver) ActualizarContrasena(ctx context.Context, email *string, oldPassword string, newPassword string) (*string, error) {
+	successMsg, err := r.Resolver.ActualizarContrasena(ctx, email, oldPassword, newPassword)
 	if err != nil {
-		return nil, errors.New("error al cifrar la nueva contraseña")
-	}
-
-	// Actualizar la contraseña
-	usuario.Password = newHashedPassword
-
-	// Guardar los cambios
-	if err := r.DB.Save(&usuario).Error; err != nil {
-		return nil, errors.New("no se pudo actualizar la contraseña")
+		return nil, err
 	}
-
-	successMsg := "Contraseña actualizada exitosamente"
 	return &successMsg, nil
 }
 
 // AddToCart is the resolver for the addToCart field.
-func (r *mutationResolver) AddToCart(ctx context.Context, username string, courseID string) (*model.Carrito, error) {
+func (r *mutationResolver) AddToCart(ctx context.Context, username *string, courseID string) (*model.Carrito, error) {
 	return r.Resolver.AddToCart(ctx, username, courseID)
 }
 
 // AddToCartByEmail es el resolver para la mutación addToCartbyEmail
-func (r *mutationResolver) AddToCartbyEmail(ctx context.Context, email string, courseID string) (*model.Carrito, error) {
+func (r *mutationResolver) AddToCartbyEmail(ctx context.Context, email *string, courseID string) (*model.Carrito, error) {
 	return r.Resolver.AddToCartbyEmail(ctx, email, courseID)
 }
 
@@ -235,7 +126,7 @@ func (r *mutationResolver) DeleteCartByCourseID(ctx context.Context, courseID st
 }
 
 // RemoveFromCart is the resolver for the removeFromCart field.
-func (r *mutationResolver) RemoveFromCart(ctx context.Context, username string, courseID string) (*bool, error) {
+func (r *mutationResolver) RemoveFromCart(ctx context.Context, username *string, courseID string) (*bool, error) {
 	success, err := r.Resolver.RemoveFromCart(ctx, username, courseID)
 	if err != nil {
 		return nil, err
@@ -244,58 +135,164 @@ func (r *mutationResolver) RemoveFromCart(ctx context.Context, username string,
 }
 
 // ViewCartByUsername is the resolver for the viewCartByUsername field.
-func (r *mutationResolver) ViewCartByUsername(ctx context.Context, username string) ([]*model.Carrito, error) {
+func (r *mutationResolver) ViewCartByUsername(ctx context.Context, username *string) ([]*model.Carrito, error) {
 	return r.Resolver.ViewCartByUsername(ctx, username)
 }
 
 // ViewCartByUserID is the resolver for the viewCartByUserID field.
-func (r *mutationResolver) ViewCartByUserID(ctx context.Context, userID string) ([]*model.Carrito, error) {
+func (r *mutationResolver) ViewCartByUserID(ctx context.Context, userID *string) ([]*model.Carrito, error) {
 	return r.Resolver.ViewCartByUserID(ctx, userID)
 }
 
 // ViewCartByEmail is the resolver for the viewCartByEmail field.
-func (r *mutationResolver) ViewCartByEmail(ctx context.Context, email string) ([]*model.Carrito, error) {
+func (r *mutationResolver) ViewCartByEmail(ctx context.Context, email *string) ([]*model.Carrito, error) {
 	return r.Resolver.ViewCartByEmail(ctx, email)
 }
 
 // DeleteUserByUsername is the resolver for the deleteUserByUsername field.
-func (r *mutationResolver) DeleteUserByUsername(ctx context.Context, username string) (string, error) {
+func (r *mutationResolver) DeleteUserByUsername(ctx context.Context, username *string) (string, error) {
 	return r.Resolver.DeleteUserByUsername(ctx, username)
 }
 
 // AddCourseToUser is the resolver for the addCourseToUser field.
-func (r *mutationResolver) AddCourseToUser(ctx context.Context, email string, courseID string) (string, error) {
+func (r *mutationResolver) AddCourseToUser(ctx context.Context, email *string, courseID string) (string, error) {
 	return r.Resolver.AddCourseToUser(ctx, email, courseID)
 }
 
-// GetUsuario maneja la consulta para obtener un usuario por su ID.
+// ActualizarRol is the resolver for the actualizarRol field.
+func (r *mutationResolver) ActualizarRol(ctx context.Context, username string, role model.Role) (*model.Usuario, error) {
+	usuario, err := r.Resolver.ActualizarRol(ctx, username, rolDesdeGraphQL(role))
+	if err != nil {
+		return nil, err
+	}
+	return usuarioGraphQL(usuario), nil
+}
+
+// Checkout is the resolver for the checkout field.
+func (r *mutationResolver) Checkout(ctx context.Context, paymentMethod string, cardToken string) (*model.Pago, error) {
+	pago, err := r.Resolver.Checkout(ctx, paymentMethod, cardToken)
+	if err != nil {
+		return nil, err
+	}
+	return pagoGraphQL(pago), nil
+}
+
+// ApprovePayment is the resolver for the approvePayment field.
+func (r *mutationResolver) ApprovePayment(ctx context.Context, paymentID string) (*model.Pago, error) {
+	pago, err := r.Resolver.AprobarPago(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	return pagoGraphQL(pago), nil
+}
+
+// RejectPayment is the resolver for the rejectPayment field.
+func (r *mutationResolver) RejectPayment(ctx context.Context, paymentID string) (*model.Pago, error) {
+	pago, err := r.Resolver.RechazarPago(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	return pagoGraphQL(pago), nil
+}
+
+// RefundPayment is the resolver for the refundPayment field.
+func (r *mutationResolver) RefundPayment(ctx context.Context, paymentID string) (*model.Pago, error) {
+	pago, err := r.Resolver.ReembolsarPago(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	return pagoGraphQL(pago), nil
+}
+
+// CreateReview is the resolver for the createReview field.
+func (r *mutationResolver) CreateReview(ctx context.Context, courseID string, rating int, comments *string) (*model.Resena, error) {
+	resena, err := r.Resolver.CrearResena(ctx, courseID, rating, comments)
+	if err != nil {
+		return nil, err
+	}
+	return resenaGraphQL(resena), nil
+}
+
+// UpdateReview is the resolver for the updateReview field.
+func (r *mutationResolver) UpdateReview(ctx context.Context, reviewID string, rating *int, comments *string) (*model.Resena, error) {
+	resena, err := r.Resolver.ActualizarResena(ctx, reviewID, rating, comments)
+	if err != nil {
+		return nil, err
+	}
+	return resenaGraphQL(resena), nil
+}
+
+// DeleteReview is the resolver for the deleteReview field.
+func (r *mutationResolver) DeleteReview(ctx context.Context, reviewID string) (bool, error) {
+	return r.Resolver.EliminarResena(ctx, reviewID)
+}
+
+// ReplyToReview is the resolver for the replyToReview field.
+func (r *mutationResolver) ReplyToReview(ctx context.Context, reviewID string, reply string) (*model.Resena, error) {
+	resena, err := r.Resolver.ResponderResena(ctx, reviewID, reply)
+	if err != nil {
+		return nil, err
+	}
+	return resenaGraphQL(resena), nil
+}
+
+// MarkNotificationRead is the resolver for the markNotificationRead field.
+func (r *mutationResolver) MarkNotificationRead(ctx context.Context, notificationID string) (*model.Notificacion, error) {
+	notificacion, err := r.Resolver.MarcarNotificacionLeida(ctx, notificationID)
+	if err != nil {
+		return nil, err
+	}
+	return notificacionGraphQL(notificacion), nil
+}
+
+// MarkAllNotificationsRead is the resolver for the markAllNotificationsRead field.
+func (r *mutationResolver) MarkAllNotificationsRead(ctx context.Context) (int, error) {
+	return r.Resolver.MarcarTodasLeidas(ctx)
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*model.Usuario, error) {
+	usuario, err := usuarioActual(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return usuarioGraphQL(usuario), nil
+}
+
+// Cart is the resolver for the cart field.
+func (r *queryResolver) Cart(ctx context.Context, first *int, after *string) (*model.Cart, error) {
+	usuario, err := usuarioActual(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Resolver.CarritoDe(ctx, usuario.UserID, first, after)
+}
+
+// CartOf is the resolver for the cartOf field.
+func (r *queryResolver) CartOf(ctx context.Context, userID string, first *int, after *string) (*model.Cart, error) {
+	return r.Resolver.CarritoDeUsuario(ctx, userID, first, after)
+}
+
+// GetUsuario maneja la consulta para obtener un usuario por su ID; solo el propio usuario o un administrador.
 func (r *queryResolver) GetUsuario(ctx context.Context, id string) (*model.Usuario, error) {
-	var usuario models.Usuario
-	if err := r.DB.First(&usuario, "user_id = ?", id).Error; err != nil {
-		return nil, errors.New("usuario no encontrado")
+	usuario, err := r.usuarioObjetivo(ctx, r.Servicios.Usuarios.PorID, &id)
+	if err != nil {
+		return nil, err
 	}
 
 	// Convertir el modelo de base de datos a modelo GraphQL
-	return &model.Usuario{
-		UserID:       usuario.UserID,
-		NameLastName: usuario.NameLastName,
-		Username:     usuario.Username,
-		Email:        usuario.Email,
-		Password:     usuario.Password,
-		Role:         usuario.Role,
-	}, nil
+	return usuarioGraphQL(usuario), nil
 }
 
 // UserByUsername is the resolver for the userByUsername field.
 func (r *queryResolver) UserByUsername(ctx context.Context, username string) (*model.Usuario, error) {
-	var usuario model.Usuario
-
-	// Buscar el usuario por nombre de usuario en la base de datos
-	if err := r.DB.Where("username = ?", username).First(&usuario).Error; err != nil {
-		return nil, fmt.Errorf("usuario no encontrado: %v", err)
+	// Buscar el usuario por nombre de usuario; solo el propio usuario o un administrador
+	usuario, err := r.usuarioObjetivo(ctx, r.Servicios.Usuarios.PorUsername, &username)
+	if err != nil {
+		return nil, err
 	}
 
-	return &usuario, nil
+	return usuarioGraphQL(usuario), nil
 }
 
 // GetAllUsers es el resolver para el campo getAllUsers.
@@ -305,35 +302,111 @@ func (r *queryResolver) GetAllUsers(ctx context.Context) ([]*model.Usuario, erro
 
 // GetCoursesByEmail is the resolver for the getCoursesByEmail field.
 func (r *queryResolver) GetCoursesByEmail(ctx context.Context, email string) ([]*model.UsuarioCurso, error) {
-	cursos, err := r.Resolver.GetCoursesByEmail(ctx, email)
+	return r.Resolver.GetCoursesByEmail(ctx, email)
+}
+
+// ObtenerUsernamePorEmail is the resolver for the obtenerUsernamePorEmail field.
+func (r *queryResolver) ObtenerUsernamePorEmail(ctx context.Context, email string) (*string, error) {
+	username, err := r.Resolver.ObtenerUsernamePorEmail(ctx, email)
 	if err != nil {
 		return nil, err
 	}
+	return &username, nil
+}
 
-	// Convertir los resultados a punteros para cumplir con el esquema GraphQL.
-	var result []*model.UsuarioCurso
-	for _, curso := range cursos {
-		cursoCopy := curso // Crear una copia para evitar referencias compartidas.
-		result = append(result, &cursoCopy)
+// MyPayments is the resolver for the myPayments field.
+func (r *queryResolver) MyPayments(ctx context.Context) ([]*model.Pago, error) {
+	pagos, err := r.Resolver.MisPagos(ctx)
+	if err != nil {
+		return nil, err
 	}
 
+	result := make([]*model.Pago, 0, len(pagos))
+	for i := range pagos {
+		result = append(result, pagoGraphQL(&pagos[i]))
+	}
 	return result, nil
 }
 
-// ObtenerUsernamePorEmail is the resolver for the obtenerUsernamePorEmail field.
-func (r *queryResolver) ObtenerUsernamePorEmail(ctx context.Context, email string) (*string, error) {
-	var usuario models.Usuario
-	if err := r.DB.Where("email = ?", email).First(&usuario).Error; err != nil {
-		return nil, errors.New("usuario no encontrado")
+// PaymentByID is the resolver for the paymentByID field.
+func (r *queryResolver) PaymentByID(ctx context.Context, paymentID string) (*model.Pago, error) {
+	pago, err := r.Resolver.PagoPorID(ctx, paymentID)
+	if err != nil {
+		return nil, err
 	}
-	return &usuario.Username, nil
+	return pagoGraphQL(pago), nil
 }
 
+// ReviewsByCourse is the resolver for the reviewsByCourse field.
+func (r *queryResolver) ReviewsByCourse(ctx context.Context, courseID string) ([]*model.Resena, error) {
+	resenas, err := r.Resolver.ResenasPorCurso(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.Resena, 0, len(resenas))
+	for i := range resenas {
+		result = append(result, resenaGraphQL(&resenas[i]))
+	}
+	return result, nil
+}
+
+// CourseRatingSummary is the resolver for the courseRatingSummary field.
+func (r *queryResolver) CourseRatingSummary(ctx context.Context, courseID string) (*model.CourseRatingSummary, error) {
+	return r.Resolver.ResumenCalificaciones(ctx, courseID)
+}
+
+// MyNotifications is the resolver for the myNotifications field.
+func (r *queryResolver) MyNotifications(ctx context.Context, status *string, first *int, after *string) (*model.NotificacionConnection, error) {
+	return r.Resolver.MisNotificaciones(ctx, status, first, after)
+}
+
+// UnreadNotificationsCount is the resolver for the unreadNotificationsCount field.
+func (r *queryResolver) UnreadNotificationsCount(ctx context.Context) (int, error) {
+	return r.Resolver.ContarNoLeidas(ctx)
+}
+
+// NotificationAdded is the resolver for the notificationAdded field.
+func (r *subscriptionResolver) NotificationAdded(ctx context.Context) (<-chan *model.Notificacion, error) {
+	usuario, err := usuarioActual(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.Notificaciones == nil {
+		return nil, errors.New("suscripciones no disponibles")
+	}
+	return r.Notificaciones.Subscribe(ctx, usuario.UserID), nil
+}
+
+// CartUpdated is the resolver for the cartUpdated field.
+func (r *subscriptionResolver) CartUpdated(ctx context.Context) (<-chan *model.CartUpdate, error) {
+	usuario, err := usuarioActual(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.Carritos == nil {
+		return nil, errors.New("suscripciones no disponibles")
+	}
+	return r.Carritos.Subscribe(ctx, usuario.UserID), nil
+}
+
+// Carrito returns CarritoResolver implementation.
+func (r *Resolver) Carrito() CarritoResolver { return &carritoResolver{r} }
+
+// Cart returns CartResolver implementation.
+func (r *Resolver) Cart() CartResolver { return &cartResolver{r} }
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+// Subscription returns SubscriptionResolver implementation.
+func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
+
+type carritoResolver struct{ *Resolver }
+type cartResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
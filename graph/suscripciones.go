@@ -0,0 +1,55 @@
+package graph
+
+import (
+	"ProyectoIngeso/events"
+	"ProyectoIngeso/graph/model"
+	"ProyectoIngeso/models"
+	"context"
+	"log"
+)
+
+// Acciones informadas en cartUpdated
+const (
+	AccionCarritoAgregado = "added"
+	AccionCarritoQuitado  = "removed"
+	AccionCarritoVaciado  = "cleared"
+	AccionCarritoComprado = "purchased"
+)
+
+// publicarNotificacion envía la notificación a las suscripciones
+// notificationAdded abiertas por su destinatario.
+func (r *Resolver) publicarNotificacion(notificacion *models.Notificación) {
+	if r.Notificaciones == nil {
+		return
+	}
+	r.Notificaciones.Publish(notificacion.UserID, notificacionGraphQL(notificacion))
+}
+
+// publicarCarrito envía el estado actual del carrito del usuario a sus
+// suscripciones cartUpdated.
+func (r *Resolver) publicarCarrito(userID string, accion string, courseID string) {
+	if r.Carritos == nil {
+		return
+	}
+
+	items, err := r.itemsCarrito(context.Background(), userID)
+	if err != nil {
+		log.Printf("No se pudo leer el carrito del usuario %s para publicarlo: %s", userID, err)
+		return
+	}
+
+	r.Carritos.Publish(userID, &model.CartUpdate{
+		UserID:   userID,
+		Action:   accion,
+		CourseID: stringOpcional(courseID),
+		Items:    items,
+	})
+}
+
+// NotificarCarritoVaciado avisa a los clientes conectados y a los demás
+// servicios que el carrito del usuario se vació fuera de GraphQL, por
+// ejemplo desde RabbitMQ.
+func (r *Resolver) NotificarCarritoVaciado(userID string) {
+	r.publicarCarrito(userID, AccionCarritoVaciado, "")
+	r.emitirEvento(context.Background(), events.CarritoVaciadoV1{UserID: userID})
+}
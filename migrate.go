@@ -0,0 +1,73 @@
+package main
+
+import (
+	"ProyectoIngeso/migrations"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// ejecutarRepair implementa el subcomando "repair", que limpia filas
+// duplicadas y huérfanas antes de aplicar las restricciones.
+func ejecutarRepair(db *gorm.DB) error {
+	reparaciones, err := migrations.Reparar(db)
+	if err != nil {
+		return err
+	}
+	for _, r := range reparaciones {
+		fmt.Printf("%-28s %d eliminadas\n", r.Nombre, r.Eliminadas)
+	}
+	return nil
+}
+
+// ejecutarMigrate implementa el subcomando "migrate up|down [pasos]|status".
+func ejecutarMigrate(db *gorm.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New("uso: migrate up | down [pasos] | status")
+	}
+
+	switch args[0] {
+	case "up":
+		hechas, err := migrations.Subir(db)
+		for _, m := range hechas {
+			fmt.Printf("aplicada %04d %s\n", m.Version, m.Nombre)
+		}
+		if err == nil && len(hechas) == 0 {
+			fmt.Println("el esquema ya está al día")
+		}
+		return err
+
+	case "down":
+		pasos := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("pasos inválidos: %q", args[1])
+			}
+			pasos = n
+		}
+		hechas, err := migrations.Bajar(db, pasos)
+		for _, m := range hechas {
+			fmt.Printf("revertida %04d %s\n", m.Version, m.Nombre)
+		}
+		return err
+
+	case "status":
+		estado, err := migrations.Estado(db)
+		if err != nil {
+			return err
+		}
+		for _, e := range estado {
+			aplicada := "pendiente"
+			if e.AplicadaEn != nil {
+				aplicada = e.AplicadaEn.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d %-30s %s\n", e.Version, e.Nombre, aplicada)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("subcomando de migrate desconocido: %q", args[0])
+}
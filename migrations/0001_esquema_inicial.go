@@ -0,0 +1,146 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// Copias de los modelos tal como estaban en esta versión. Las migraciones no
+// usan los tipos de models para que un cambio posterior no altere su efecto.
+
+type usuario0001 struct {
+	UserID       string `gorm:"primaryKey;type:text"`
+	NameLastName string
+	Username     string `gorm:"uniqueIndex"`
+	Email        string `gorm:"uniqueIndex"`
+	Password     string
+	Role         string
+}
+
+func (usuario0001) TableName() string { return "usuarios" }
+
+type carrito0001 struct {
+	CartID   string `gorm:"primaryKey;type:text"`
+	UserID   string `gorm:"type:text"`
+	CourseID string `gorm:"type:text"`
+}
+
+func (carrito0001) TableName() string { return "carritos" }
+
+type usuarioCurso0001 struct {
+	ID       string `gorm:"primaryKey;type:text"`
+	Email    string `gorm:"type:text"`
+	CourseID string `gorm:"type:text"`
+}
+
+func (usuarioCurso0001) TableName() string { return "usuario_cursos" }
+
+type resena0001 struct {
+	ReviewID  string `gorm:"primaryKey;type:text"`
+	UserID    string `gorm:"not null;type:text;uniqueIndex:idx_resena_usuario_curso"`
+	CourseID  string `gorm:"not null;type:text;uniqueIndex:idx_resena_usuario_curso;index"`
+	Rating    int
+	Comments  string
+	CreatedAt string
+	UpdatedAt string
+	Reply     string
+	RepliedAt string
+}
+
+func (resena0001) TableName() string { return "reseñas" }
+
+type pago0001 struct {
+	PaymentID     string `gorm:"primaryKey;type:text"`
+	UserID        string `gorm:"not null;type:text"`
+	Amount        float64
+	Status        string
+	PaymentMethod string
+	PaymentDate   string
+	GatewayRef    string `gorm:"type:text;index"`
+}
+
+func (pago0001) TableName() string { return "pagos" }
+
+type pagoItem0001 struct {
+	ItemID    string `gorm:"primaryKey;type:text"`
+	PaymentID string `gorm:"not null;type:text;index"`
+	CourseID  string `gorm:"not null;type:text"`
+	Price     float64
+}
+
+func (pagoItem0001) TableName() string { return "pago_items" }
+
+type notificacion0001 struct {
+	NotificationID string `gorm:"primaryKey;type:text"`
+	UserID         string `gorm:"not null;type:text;index:idx_notificacion_usuario"`
+	Type           string `gorm:"type:text"`
+	Message        string
+	Status         string `gorm:"index:idx_notificacion_usuario"`
+	CreatedAt      string
+}
+
+func (notificacion0001) TableName() string { return "notificacións" }
+
+// esquemaInicial crea las tablas existentes antes de versionar el esquema.
+// Las bases creadas por la versión anterior ya tienen parte de estas tablas;
+// se adoptan agregando solo lo que falte.
+var esquemaInicial = Migracion{
+	Version: 1,
+	Nombre:  "esquema_inicial",
+	Subir: func(tx *gorm.DB) error {
+		return crearOCompletar(tx,
+			&usuario0001{},
+			&carrito0001{},
+			&usuarioCurso0001{},
+			&resena0001{},
+			&pago0001{},
+			&pagoItem0001{},
+			&notificacion0001{},
+		)
+	},
+	Bajar: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(
+			&notificacion0001{},
+			&pagoItem0001{},
+			&pago0001{},
+			&resena0001{},
+			&usuarioCurso0001{},
+			&carrito0001{},
+			&usuario0001{},
+		)
+	},
+}
+
+// crearOCompletar crea cada tabla o, si ya existe, le agrega las columnas e
+// índices que falten. No usa AutoMigrate porque el driver de SQLite no sabe
+// leer la definición de tablas con nombres no ASCII como reseñas.
+func crearOCompletar(tx *gorm.DB, modelos ...interface{}) error {
+	migrator := tx.Migrator()
+	for _, modelo := range modelos {
+		if !migrator.HasTable(modelo) {
+			if err := migrator.CreateTable(modelo); err != nil {
+				return err
+			}
+			continue
+		}
+
+		stmt := &gorm.Statement{DB: tx}
+		if err := stmt.Parse(modelo); err != nil {
+			return err
+		}
+		for _, campo := range stmt.Schema.Fields {
+			if campo.DBName == "" || migrator.HasColumn(modelo, campo.DBName) {
+				continue
+			}
+			if err := migrator.AddColumn(modelo, campo.DBName); err != nil {
+				return err
+			}
+		}
+		for nombre := range stmt.Schema.ParseIndexes() {
+			if migrator.HasIndex(modelo, nombre) {
+				continue
+			}
+			if err := migrator.CreateIndex(modelo, nombre); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package migrations
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// tablaReconstruida es una tabla de SQLite que se vuelve a crear porque
+// SQLite no permite agregar claves foráneas a una tabla existente.
+type tablaReconstruida struct {
+	Nombre   string
+	Columnas string
+	Crear    string   // Definición de columnas y restricciones
+	Indices  []string // Se pierden al borrar la tabla vieja
+	// Origen es el SELECT que llena la tabla nueva con Columnas. Si está
+	// vacío se copian las mismas columnas de la tabla vieja.
+	Origen string
+}
+
+// Versión 2 de las tablas en SQLite. usuarios se reconstruye solo para
+// quitar las claves foráneas invertidas que creó AutoMigrate en versiones
+// anteriores (usuarios.user_id apuntando a reseñas, pagos y notificaciones).
+var tablasV2 = []tablaReconstruida{
+	{
+		Nombre:   "usuarios",
+		Columnas: "user_id, name_last_name, username, email, password, role",
+		Crear: `user_id text PRIMARY KEY, name_last_name text, username text, email text,
+			password text, role text`,
+		Indices: []string{
+			`CREATE UNIQUE INDEX idx_usuarios_email ON usuarios(email)`,
+			`CREATE UNIQUE INDEX idx_usuarios_username ON usuarios(username)`,
+		},
+	},
+	{
+		Nombre:   "carritos",
+		Columnas: "cart_id, user_id, course_id",
+		Crear: `cart_id text PRIMARY KEY,
+			user_id text NOT NULL REFERENCES usuarios(user_id) ON DELETE CASCADE,
+			course_id text NOT NULL`,
+		Indices: []string{
+			`CREATE UNIQUE INDEX idx_carrito_usuario_curso ON carritos(user_id, course_id)`,
+		},
+	},
+	{
+		Nombre:   "usuario_cursos",
+		Columnas: "id, email, course_id",
+		Crear: `id text PRIMARY KEY,
+			email text NOT NULL REFERENCES usuarios(email) ON UPDATE CASCADE ON DELETE CASCADE,
+			course_id text NOT NULL`,
+		Indices: []string{
+			`CREATE UNIQUE INDEX idx_usuario_curso_email_curso ON usuario_cursos(email, course_id)`,
+		},
+	},
+	{
+		Nombre:   "reseñas",
+		Columnas: "review_id, user_id, course_id, rating, comments, created_at, updated_at, reply, replied_at",
+		Crear: `review_id text PRIMARY KEY,
+			user_id text NOT NULL REFERENCES usuarios(user_id) ON DELETE CASCADE,
+			course_id text NOT NULL, rating integer, comments text, created_at text,
+			updated_at text, reply text, replied_at text`,
+		Indices: []string{
+			`CREATE UNIQUE INDEX idx_resena_usuario_curso ON "reseñas"(user_id, course_id)`,
+			`CREATE INDEX "idx_reseñas_course_id" ON "reseñas"(course_id)`,
+		},
+	},
+	{
+		Nombre:   "pagos",
+		Columnas: "payment_id, user_id, amount, status, payment_method, payment_date, gateway_ref",
+		Crear: `payment_id text PRIMARY KEY,
+			user_id text NOT NULL REFERENCES usuarios(user_id) ON DELETE CASCADE,
+			amount real, status text, payment_method text, payment_date text, gateway_ref text`,
+		Indices: []string{
+			`CREATE INDEX idx_pagos_gateway_ref ON pagos(gateway_ref)`,
+		},
+	},
+	{
+		Nombre:   "pago_items",
+		Columnas: "item_id, payment_id, course_id, price",
+		Crear: `item_id text PRIMARY KEY,
+			payment_id text NOT NULL REFERENCES pagos(payment_id) ON DELETE CASCADE,
+			course_id text NOT NULL, price real`,
+		Indices: []string{
+			`CREATE INDEX idx_pago_items_payment_id ON pago_items(payment_id)`,
+			`CREATE UNIQUE INDEX idx_pago_item_pago_curso ON pago_items(payment_id, course_id)`,
+		},
+	},
+	{
+		Nombre:   "notificacións",
+		Columnas: "notification_id, user_id, type, message, status, created_at",
+		Crear: `notification_id text PRIMARY KEY,
+			user_id text NOT NULL REFERENCES usuarios(user_id) ON DELETE CASCADE,
+			type text, message text, status text, created_at text`,
+		Indices: []string{
+			`CREATE INDEX idx_notificacion_usuario ON "notificacións"(user_id, status)`,
+		},
+	},
+}
+
+// Versión 1 de las mismas tablas, para revertir en SQLite.
+var tablasV1 = []tablaReconstruida{
+	{
+		Nombre:   "notificacións",
+		Columnas: "notification_id, user_id, type, message, status, created_at",
+		Crear: `notification_id text PRIMARY KEY, user_id text NOT NULL, type text, message text,
+			status text, created_at text`,
+		Indices: []string{
+			`CREATE INDEX idx_notificacion_usuario ON "notificacións"(user_id, status)`,
+		},
+	},
+	{
+		Nombre:   "pago_items",
+		Columnas: "item_id, payment_id, course_id, price",
+		Crear:    `item_id text PRIMARY KEY, payment_id text NOT NULL, course_id text NOT NULL, price real`,
+		Indices: []string{
+			`CREATE INDEX idx_pago_items_payment_id ON pago_items(payment_id)`,
+		},
+	},
+	{
+		Nombre:   "pagos",
+		Columnas: "payment_id, user_id, amount, status, payment_method, payment_date, gateway_ref",
+		Crear: `payment_id text PRIMARY KEY, user_id text NOT NULL, amount real, status text,
+			payment_method text, payment_date text, gateway_ref text`,
+		Indices: []string{
+			`CREATE INDEX idx_pagos_gateway_ref ON pagos(gateway_ref)`,
+		},
+	},
+	{
+		Nombre:   "reseñas",
+		Columnas: "review_id, user_id, course_id, rating, comments, created_at, updated_at, reply, replied_at",
+		Crear: `review_id text PRIMARY KEY, user_id text NOT NULL, course_id text NOT NULL,
+			rating integer, comments text, created_at text, updated_at text, reply text, replied_at text`,
+		Indices: []string{
+			`CREATE UNIQUE INDEX idx_resena_usuario_curso ON "reseñas"(user_id, course_id)`,
+			`CREATE INDEX "idx_reseñas_course_id" ON "reseñas"(course_id)`,
+		},
+	},
+	{
+		Nombre:   "usuario_cursos",
+		Columnas: "id, email, course_id",
+		Crear:    `id text PRIMARY KEY, email text, course_id text`,
+	},
+	{
+		Nombre:   "carritos",
+		Columnas: "cart_id, user_id, course_id",
+		Crear:    `cart_id text PRIMARY KEY, user_id text, course_id text`,
+	},
+}
+
+// Restricciones de la versión 2 en PostgreSQL, que sí permite agregarlas a
+// tablas existentes. Cada par es {crear, eliminar}.
+var restriccionesPostgres = [][2]string{
+	{`ALTER TABLE carritos ADD CONSTRAINT fk_carritos_usuario
+		FOREIGN KEY (user_id) REFERENCES usuarios(user_id) ON DELETE CASCADE`,
+		`ALTER TABLE carritos DROP CONSTRAINT fk_carritos_usuario`},
+	{`CREATE UNIQUE INDEX idx_carrito_usuario_curso ON carritos(user_id, course_id)`,
+		`DROP INDEX idx_carrito_usuario_curso`},
+	{`ALTER TABLE usuario_cursos ADD CONSTRAINT fk_usuario_cursos_usuario
+		FOREIGN KEY (email) REFERENCES usuarios(email) ON UPDATE CASCADE ON DELETE CASCADE`,
+		`ALTER TABLE usuario_cursos DROP CONSTRAINT fk_usuario_cursos_usuario`},
+	{`CREATE UNIQUE INDEX idx_usuario_curso_email_curso ON usuario_cursos(email, course_id)`,
+		`DROP INDEX idx_usuario_curso_email_curso`},
+	{`ALTER TABLE "reseñas" ADD CONSTRAINT "fk_reseñas_usuario"
+		FOREIGN KEY (user_id) REFERENCES usuarios(user_id) ON DELETE CASCADE`,
+		`ALTER TABLE "reseñas" DROP CONSTRAINT "fk_reseñas_usuario"`},
+	{`ALTER TABLE pagos ADD CONSTRAINT fk_pagos_usuario
+		FOREIGN KEY (user_id) REFERENCES usuarios(user_id) ON DELETE CASCADE`,
+		`ALTER TABLE pagos DROP CONSTRAINT fk_pagos_usuario`},
+	{`ALTER TABLE pago_items ADD CONSTRAINT fk_pago_items_pago
+		FOREIGN KEY (payment_id) REFERENCES pagos(payment_id) ON DELETE CASCADE`,
+		`ALTER TABLE pago_items DROP CONSTRAINT fk_pago_items_pago`},
+	{`CREATE UNIQUE INDEX idx_pago_item_pago_curso ON pago_items(payment_id, course_id)`,
+		`DROP INDEX idx_pago_item_pago_curso`},
+	{`ALTER TABLE "notificacións" ADD CONSTRAINT "fk_notificacións_usuario"
+		FOREIGN KEY (user_id) REFERENCES usuarios(user_id) ON DELETE CASCADE`,
+		`ALTER TABLE "notificacións" DROP CONSTRAINT "fk_notificacións_usuario"`},
+}
+
+// restricciones agrega claves foráneas con borrado en cascada hacia usuarios
+// y pagos, e índices únicos que impiden cursos repetidos en un carrito, una
+// inscripción o un pago. Requiere datos limpios: ver Reparar.
+var restricciones = Migracion{
+	Version: 2,
+	Nombre:  "restricciones",
+	Subir: func(tx *gorm.DB) error {
+		if err := verificarIntegridad(tx); err != nil {
+			return err
+		}
+		if tx.Dialector.Name() == "sqlite" {
+			return reconstruirTablas(tx, tablasV2)
+		}
+		for _, r := range restriccionesPostgres {
+			if err := tx.Exec(r[0]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Bajar: func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "sqlite" {
+			return reconstruirTablas(tx, tablasV1)
+		}
+		for i := len(restriccionesPostgres) - 1; i >= 0; i-- {
+			if err := tx.Exec(restriccionesPostgres[i][1]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// reconstruirTablas reemplaza cada tabla por una nueva con la definición
+// indicada, copiando sus filas. El orden importa: una tabla solo puede
+// borrarse cuando ninguna otra la referencia todavía. Usa SQL común a SQLite
+// y PostgreSQL, salvo el nombre de la clave primaria en PostgreSQL.
+func reconstruirTablas(tx *gorm.DB, tablas []tablaReconstruida) error {
+	for _, t := range tablas {
+		nueva := t.Nombre + "__nueva"
+		origen := t.Origen
+		if origen == "" {
+			origen = fmt.Sprintf(`SELECT %s FROM "%s"`, t.Columnas, t.Nombre)
+		}
+		sentencias := []string{
+			fmt.Sprintf(`CREATE TABLE "%s" (%s)`, nueva, t.Crear),
+			fmt.Sprintf(`INSERT INTO "%s" (%s) %s`, nueva, t.Columnas, origen),
+			fmt.Sprintf(`DROP TABLE "%s"`, t.Nombre),
+			fmt.Sprintf(`ALTER TABLE "%s" RENAME TO "%s"`, nueva, t.Nombre),
+		}
+		if tx.Dialector.Name() == "postgres" {
+			// PostgreSQL no renombra la clave primaria con la tabla; si quedara
+			// como <tabla>__nueva_pkey, la próxima reconstrucción chocaría con ella
+			sentencias = append(sentencias, fmt.Sprintf(`ALTER TABLE "%s" RENAME CONSTRAINT "%s_pkey" TO "%s_pkey"`,
+				t.Nombre, nueva, t.Nombre))
+		}
+		for _, sentencia := range append(sentencias, t.Indices...) {
+			if err := tx.Exec(sentencia).Error; err != nil {
+				return fmt.Errorf("error al reconstruir %s: %w", t.Nombre, err)
+			}
+		}
+	}
+	return nil
+}
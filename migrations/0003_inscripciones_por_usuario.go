@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// inscripcionesPorUsuario cambia la clave de usuario_cursos del email al
+// user_id, para que cambiar el email no afecte las inscripciones, y agrega
+// la fecha, el origen (purchase, gift o admin) y el pago de cada inscripción.
+// Las inscripciones existentes que coinciden con un pago aprobado se marcan
+// como compras con la fecha de ese pago; el resto queda como admin.
+var inscripcionesPorUsuario = Migracion{
+	Version: 3,
+	Nombre:  "inscripciones_por_usuario",
+	Subir: func(tx *gorm.DB) error {
+		ahora := time.Now().UTC().Format(time.RFC3339)
+		return reconstruirTablas(tx, []tablaReconstruida{{
+			Nombre:   "usuario_cursos",
+			Columnas: "id, user_id, course_id, enrolled_at, source, payment_id",
+			Crear: `id text PRIMARY KEY,
+				user_id text NOT NULL,
+				course_id text NOT NULL,
+				enrolled_at text NOT NULL,
+				source text NOT NULL,
+				payment_id text,
+				CONSTRAINT fk_usuario_cursos_usuario FOREIGN KEY (user_id)
+					REFERENCES usuarios(user_id) ON DELETE CASCADE,
+				CONSTRAINT fk_usuario_cursos_pago FOREIGN KEY (payment_id)
+					REFERENCES pagos(payment_id) ON DELETE SET NULL`,
+			Origen: fmt.Sprintf(`
+				SELECT uc.id, u.user_id, uc.course_id,
+					COALESCE(p.payment_date, '%s'),
+					CASE WHEN p.payment_id IS NULL THEN 'admin' ELSE 'purchase' END,
+					p.payment_id
+				FROM usuario_cursos uc
+				JOIN usuarios u ON u.email = uc.email
+				LEFT JOIN pagos p ON p.payment_id = (
+					SELECT MIN(pa.payment_id) FROM pagos pa
+					JOIN pago_items pi ON pi.payment_id = pa.payment_id
+					WHERE pa.user_id = u.user_id AND pi.course_id = uc.course_id
+						AND pa.status = 'approved')`, ahora),
+			Indices: []string{
+				`CREATE UNIQUE INDEX idx_usuario_curso_usuario_curso ON usuario_cursos(user_id, course_id)`,
+				`CREATE INDEX idx_usuario_cursos_payment_id ON usuario_cursos(payment_id)`,
+			},
+		}})
+	},
+	Bajar: func(tx *gorm.DB) error {
+		return reconstruirTablas(tx, []tablaReconstruida{{
+			Nombre:   "usuario_cursos",
+			Columnas: "id, email, course_id",
+			Crear: `id text PRIMARY KEY,
+				email text NOT NULL,
+				course_id text NOT NULL,
+				CONSTRAINT fk_usuario_cursos_usuario FOREIGN KEY (email)
+					REFERENCES usuarios(email) ON UPDATE CASCADE ON DELETE CASCADE`,
+			Origen: `
+				SELECT uc.id, u.email, uc.course_id
+				FROM usuario_cursos uc
+				JOIN usuarios u ON u.user_id = uc.user_id`,
+			Indices: []string{
+				`CREATE UNIQUE INDEX idx_usuario_curso_email_curso ON usuario_cursos(email, course_id)`,
+			},
+		}})
+	},
+}
@@ -0,0 +1,170 @@
+// Package migrations versiona el esquema de la base de datos. Cada migración
+// es reversible y queda registrada en la tabla schema_migrations al aplicarse.
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migracion es un cambio de esquema con su reverso. Subir y Bajar corren
+// dentro de una transacción junto con el registro en schema_migrations.
+type Migracion struct {
+	Version int
+	Nombre  string
+	Subir   func(tx *gorm.DB) error
+	Bajar   func(tx *gorm.DB) error
+}
+
+// migraciones conocidas por este binario, en orden de versión. Una migración
+// publicada no se modifica: los cambios nuevos van en una versión nueva.
+var migraciones = []Migracion{
+	esquemaInicial,
+	restricciones,
+	inscripcionesPorUsuario,
+}
+
+var (
+	// ErrEsquemaDesactualizado indica que hay migraciones sin aplicar.
+	ErrEsquemaDesactualizado = errors.New("el esquema de la base de datos está desactualizado")
+	// ErrEsquemaDesconocido indica que la base tiene migraciones que este
+	// binario no conoce, probablemente de una versión más nueva del servicio.
+	ErrEsquemaDesconocido = errors.New("la base de datos tiene migraciones desconocidas")
+)
+
+// registroMigracion es una fila de schema_migrations.
+type registroMigracion struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (registroMigracion) TableName() string {
+	return "schema_migrations"
+}
+
+// EstadoMigracion describe una migración y si ya está aplicada.
+type EstadoMigracion struct {
+	Version    int
+	Nombre     string
+	AplicadaEn *time.Time
+}
+
+// Subir aplica en orden las migraciones pendientes y devuelve las aplicadas.
+// Si una falla, las anteriores quedan aplicadas y la fallida se revierte.
+func Subir(db *gorm.DB) ([]Migracion, error) {
+	registradas, err := aplicadas(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var hechas []Migracion
+	for _, m := range migraciones {
+		if _, ok := registradas[m.Version]; ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Subir(tx); err != nil {
+				return err
+			}
+			return tx.Create(&registroMigracion{Version: m.Version, Name: m.Nombre, AppliedAt: time.Now().UTC()}).Error
+		})
+		if err != nil {
+			return hechas, fmt.Errorf("error al aplicar la migración %d (%s): %w", m.Version, m.Nombre, err)
+		}
+		hechas = append(hechas, m)
+	}
+	return hechas, nil
+}
+
+// Bajar revierte las últimas pasos migraciones aplicadas, de la más nueva a
+// la más vieja, y devuelve las revertidas.
+func Bajar(db *gorm.DB, pasos int) ([]Migracion, error) {
+	registradas, err := aplicadas(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var hechas []Migracion
+	for i := len(migraciones) - 1; i >= 0 && len(hechas) < pasos; i-- {
+		m := migraciones[i]
+		if _, ok := registradas[m.Version]; !ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Bajar(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&registroMigracion{}, m.Version).Error
+		})
+		if err != nil {
+			return hechas, fmt.Errorf("error al revertir la migración %d (%s): %w", m.Version, m.Nombre, err)
+		}
+		hechas = append(hechas, m)
+	}
+	return hechas, nil
+}
+
+// Estado lista todas las migraciones conocidas con su fecha de aplicación.
+func Estado(db *gorm.DB) ([]EstadoMigracion, error) {
+	registradas, err := aplicadas(db)
+	if err != nil {
+		return nil, err
+	}
+
+	estado := make([]EstadoMigracion, 0, len(migraciones))
+	for _, m := range migraciones {
+		e := EstadoMigracion{Version: m.Version, Nombre: m.Nombre}
+		if r, ok := registradas[m.Version]; ok {
+			e.AplicadaEn = &r.AppliedAt
+		}
+		estado = append(estado, e)
+	}
+	return estado, nil
+}
+
+// VerificarAlDia falla si el esquema no coincide exactamente con las
+// migraciones de este binario. Se llama antes de atender peticiones.
+func VerificarAlDia(db *gorm.DB) error {
+	registradas, err := aplicadas(db)
+	if err != nil {
+		return err
+	}
+
+	pendientes := 0
+	for _, m := range migraciones {
+		if _, ok := registradas[m.Version]; ok {
+			delete(registradas, m.Version)
+		} else {
+			pendientes++
+		}
+	}
+	if len(registradas) > 0 {
+		return fmt.Errorf("%w (%d)", ErrEsquemaDesconocido, len(registradas))
+	}
+	if pendientes > 0 {
+		return fmt.Errorf("%w: faltan %d migraciones", ErrEsquemaDesactualizado, pendientes)
+	}
+	return nil
+}
+
+// aplicadas crea schema_migrations si no existe y devuelve sus filas por versión.
+func aplicadas(db *gorm.DB) (map[int]registroMigracion, error) {
+	if err := db.AutoMigrate(&registroMigracion{}); err != nil {
+		return nil, fmt.Errorf("no se pudo crear la tabla schema_migrations: %w", err)
+	}
+
+	var filas []registroMigracion
+	if err := db.Order("version").Find(&filas).Error; err != nil {
+		return nil, fmt.Errorf("no se pudieron leer las migraciones aplicadas: %w", err)
+	}
+
+	registradas := make(map[int]registroMigracion, len(filas))
+	for _, f := range filas {
+		registradas[f.Version] = f
+	}
+	return registradas, nil
+}
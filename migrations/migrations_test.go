@@ -0,0 +1,203 @@
+package migrations
+
+import (
+	"errors"
+	"testing"
+
+	"ProyectoIngeso/database/dbtest"
+
+	"gorm.io/gorm"
+)
+
+func subir(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	if _, err := Subir(db); err != nil {
+		t.Fatalf("Subir: %s", err)
+	}
+}
+
+func bajar(t *testing.T, db *gorm.DB, pasos int) {
+	t.Helper()
+	hechas, err := Bajar(db, pasos)
+	if err != nil {
+		t.Fatalf("Bajar(%d): %s", pasos, err)
+	}
+	if len(hechas) != pasos {
+		t.Fatalf("Bajar(%d) revirtió %d migraciones", pasos, len(hechas))
+	}
+}
+
+func exec(t *testing.T, db *gorm.DB, sentencias ...string) {
+	t.Helper()
+	for _, s := range sentencias {
+		if err := db.Exec(s).Error; err != nil {
+			t.Fatalf("%s: %s", s, err)
+		}
+	}
+}
+
+func contar(t *testing.T, db *gorm.DB, tabla string) int64 {
+	t.Helper()
+	var total int64
+	if err := db.Table(tabla).Count(&total).Error; err != nil {
+		t.Fatal(err)
+	}
+	return total
+}
+
+func TestSubirYBajarTodas(t *testing.T) {
+	dbtest.ParaCadaMotor(t, func(t *testing.T, db *gorm.DB) {
+		if err := VerificarAlDia(db); !errors.Is(err, ErrEsquemaDesactualizado) {
+			t.Fatalf("VerificarAlDia en una base vacía = %v", err)
+		}
+
+		hechas, err := Subir(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hechas) != len(migraciones) {
+			t.Fatalf("se aplicaron %d de %d migraciones", len(hechas), len(migraciones))
+		}
+		if err := VerificarAlDia(db); err != nil {
+			t.Fatal(err)
+		}
+		estado, err := Estado(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range estado {
+			if e.AplicadaEn == nil {
+				t.Errorf("la migración %d no figura como aplicada", e.Version)
+			}
+		}
+		if hechas, err := Subir(db); err != nil || len(hechas) != 0 {
+			t.Fatalf("Subir con el esquema al día = %d, %v", len(hechas), err)
+		}
+
+		// Bajar de a una ejercita cada reverso sobre el esquema que dejó el anterior
+		for range migraciones {
+			bajar(t, db, 1)
+		}
+		for _, tabla := range []string{"usuarios", "carritos", "usuario_cursos", "reseñas", "pagos", "pago_items", "notificacións"} {
+			if db.Migrator().HasTable(tabla) {
+				t.Errorf("la tabla %s sigue existiendo", tabla)
+			}
+		}
+
+		// Una segunda vuelta completa detecta nombres que quedaron ocupados
+		subir(t, db)
+		bajar(t, db, len(migraciones))
+		subir(t, db)
+		if err := VerificarAlDia(db); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestVerificarAlDiaConMigracionDesconocida(t *testing.T) {
+	dbtest.ParaCadaMotor(t, func(t *testing.T, db *gorm.DB) {
+		subir(t, db)
+		exec(t, db, `INSERT INTO schema_migrations (version, name, applied_at) VALUES (999, 'futura', CURRENT_TIMESTAMP)`)
+		if err := VerificarAlDia(db); !errors.Is(err, ErrEsquemaDesconocido) {
+			t.Fatalf("VerificarAlDia = %v, se esperaba ErrEsquemaDesconocido", err)
+		}
+	})
+}
+
+func TestInscripcionesPorUsuarioConservaLosDatos(t *testing.T) {
+	dbtest.ParaCadaMotor(t, func(t *testing.T, db *gorm.DB) {
+		subir(t, db)
+		bajar(t, db, 1)
+
+		exec(t, db,
+			`INSERT INTO usuarios (user_id, username, email) VALUES ('u1', 'ana', 'ana@ejemplo.com')`,
+			`INSERT INTO pagos (payment_id, user_id, amount, status, payment_date) VALUES ('p1', 'u1', 10, 'approved', '2024-05-01T10:00:00Z')`,
+			`INSERT INTO pagos (payment_id, user_id, amount, status, payment_date) VALUES ('p2', 'u1', 20, 'rejected', '2024-05-02T10:00:00Z')`,
+			`INSERT INTO pago_items (item_id, payment_id, course_id, price) VALUES ('i1', 'p1', 'c1', 10)`,
+			`INSERT INTO pago_items (item_id, payment_id, course_id, price) VALUES ('i2', 'p2', 'c2', 20)`,
+			`INSERT INTO usuario_cursos (id, email, course_id) VALUES ('e1', 'ana@ejemplo.com', 'c1')`,
+			`INSERT INTO usuario_cursos (id, email, course_id) VALUES ('e2', 'ana@ejemplo.com', 'c2')`,
+		)
+		subir(t, db)
+
+		type inscripcion struct {
+			ID         string
+			UserID     string
+			CourseID   string
+			EnrolledAt string
+			Source     string
+			PaymentID  *string
+		}
+		var filas []inscripcion
+		if err := db.Table("usuario_cursos").Order("id").Find(&filas).Error; err != nil {
+			t.Fatal(err)
+		}
+		if len(filas) != 2 {
+			t.Fatalf("quedaron %d inscripciones, se esperaban 2", len(filas))
+		}
+		compra, admin := filas[0], filas[1]
+		if compra.UserID != "u1" || compra.Source != "purchase" || compra.PaymentID == nil ||
+			*compra.PaymentID != "p1" || compra.EnrolledAt != "2024-05-01T10:00:00Z" {
+			t.Errorf("inscripción comprada = %+v", compra)
+		}
+		// El pago rechazado no cuenta como compra
+		if admin.UserID != "u1" || admin.Source != "admin" || admin.PaymentID != nil || admin.EnrolledAt == "" {
+			t.Errorf("inscripción sin pago aprobado = %+v", admin)
+		}
+
+		bajar(t, db, 1)
+		var emails []string
+		if err := db.Table("usuario_cursos").Order("id").Pluck("email", &emails).Error; err != nil {
+			t.Fatal(err)
+		}
+		if len(emails) != 2 || emails[0] != "ana@ejemplo.com" || emails[1] != "ana@ejemplo.com" {
+			t.Errorf("emails al revertir = %v", emails)
+		}
+	})
+}
+
+func TestRepararPermiteAplicarLasRestricciones(t *testing.T) {
+	dbtest.ParaCadaMotor(t, func(t *testing.T, db *gorm.DB) {
+		subir(t, db)
+		bajar(t, db, 2)
+
+		exec(t, db,
+			`INSERT INTO usuarios (user_id, username, email) VALUES ('u1', 'ana', 'ana@ejemplo.com')`,
+			`INSERT INTO carritos (cart_id, user_id, course_id) VALUES ('a1', 'u1', 'c1')`,
+			`INSERT INTO carritos (cart_id, user_id, course_id) VALUES ('a2', 'u1', 'c1')`,
+			`INSERT INTO carritos (cart_id, user_id, course_id) VALUES ('a3', 'nadie', 'c1')`,
+			`INSERT INTO usuario_cursos (id, email, course_id) VALUES ('e1', 'ana@ejemplo.com', 'c1')`,
+			`INSERT INTO usuario_cursos (id, email, course_id) VALUES ('e2', 'ana@ejemplo.com', 'c1')`,
+			`INSERT INTO pagos (payment_id, user_id, status) VALUES ('p1', 'nadie', 'approved')`,
+			`INSERT INTO pago_items (item_id, payment_id, course_id) VALUES ('i1', 'p1', 'c1')`,
+		)
+
+		if _, err := Subir(db); err == nil {
+			t.Fatal("Subir aplicó las restricciones con datos inválidos")
+		}
+		if err := VerificarAlDia(db); !errors.Is(err, ErrEsquemaDesactualizado) {
+			t.Fatalf("VerificarAlDia tras la falla = %v", err)
+		}
+
+		reparaciones, err := Reparar(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var eliminadas int64
+		for _, r := range reparaciones {
+			eliminadas += r.Eliminadas
+		}
+		// Dos carritos, una inscripción, un pago y su item
+		if eliminadas != 5 {
+			t.Errorf("Reparar eliminó %d filas, se esperaban 5: %+v", eliminadas, reparaciones)
+		}
+
+		subir(t, db)
+		if n := contar(t, db, "carritos"); n != 1 {
+			t.Errorf("quedaron %d items de carrito, se esperaba 1", n)
+		}
+		if n := contar(t, db, "usuario_cursos"); n != 1 {
+			t.Errorf("quedaron %d inscripciones, se esperaba 1", n)
+		}
+	})
+}
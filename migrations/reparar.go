@@ -0,0 +1,103 @@
+package migrations
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// reglaIntegridad describe filas que violan una restricción de la versión 2:
+// las que cumplen Condicion sobran en Tabla. Si Columna no está vacía, la
+// regla solo aplica mientras la tabla tenga esa columna.
+type reglaIntegridad struct {
+	Nombre    string
+	Tabla     string
+	Condicion string
+	Columna   string
+}
+
+// reglasIntegridad en el orden en que se reparan; los pagos huérfanos se
+// borran antes que los items para que sus items también cuenten como huérfanos.
+var reglasIntegridad = []reglaIntegridad{
+	{"carritos huérfanos", "carritos",
+		`user_id IS NULL OR user_id NOT IN (SELECT user_id FROM usuarios)`, ""},
+	{"carritos duplicados", "carritos",
+		`cart_id NOT IN (SELECT MIN(cart_id) FROM carritos GROUP BY user_id, course_id)`, ""},
+	// Hasta la versión 3 las inscripciones se identifican por email
+	{"inscripciones huérfanas", "usuario_cursos",
+		`email IS NULL OR email NOT IN (SELECT email FROM usuarios WHERE email IS NOT NULL)`, "email"},
+	{"inscripciones duplicadas", "usuario_cursos",
+		`id NOT IN (SELECT MIN(id) FROM usuario_cursos GROUP BY email, course_id)`, "email"},
+	{"inscripciones huérfanas", "usuario_cursos",
+		`user_id NOT IN (SELECT user_id FROM usuarios)`, "user_id"},
+	{"inscripciones duplicadas", "usuario_cursos",
+		`id NOT IN (SELECT MIN(id) FROM usuario_cursos GROUP BY user_id, course_id)`, "user_id"},
+	{"reseñas huérfanas", `"reseñas"`,
+		`user_id NOT IN (SELECT user_id FROM usuarios)`, ""},
+	{"notificaciones huérfanas", `"notificacións"`,
+		`user_id NOT IN (SELECT user_id FROM usuarios)`, ""},
+	{"pagos huérfanos", "pagos",
+		`user_id NOT IN (SELECT user_id FROM usuarios)`, ""},
+	{"items de pago huérfanos", "pago_items",
+		`payment_id NOT IN (SELECT payment_id FROM pagos)`, ""},
+	{"items de pago duplicados", "pago_items",
+		`item_id NOT IN (SELECT MIN(item_id) FROM pago_items GROUP BY payment_id, course_id)`, ""},
+}
+
+// Reparacion cuenta las filas eliminadas por cada regla.
+type Reparacion struct {
+	Nombre     string
+	Eliminadas int64
+}
+
+// Reparar elimina en una transacción las filas duplicadas y huérfanas que
+// impedirían aplicar las restricciones de la versión 2. De cada grupo de
+// duplicados conserva la fila con el ID menor.
+func Reparar(db *gorm.DB) ([]Reparacion, error) {
+	var resultado []Reparacion
+	err := db.Transaction(func(tx *gorm.DB) error {
+		resultado = resultado[:0]
+		for _, regla := range reglasAplicables(tx) {
+			res := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s", regla.Tabla, regla.Condicion))
+			if res.Error != nil {
+				return fmt.Errorf("error al reparar %s: %w", regla.Nombre, res.Error)
+			}
+			resultado = append(resultado, Reparacion{Nombre: regla.Nombre, Eliminadas: res.RowsAffected})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resultado, nil
+}
+
+// reglasAplicables filtra las reglas según las columnas actuales.
+func reglasAplicables(db *gorm.DB) []reglaIntegridad {
+	var reglas []reglaIntegridad
+	for _, regla := range reglasIntegridad {
+		if regla.Columna == "" || db.Migrator().HasColumn(regla.Tabla, regla.Columna) {
+			reglas = append(reglas, regla)
+		}
+	}
+	return reglas
+}
+
+// verificarIntegridad falla si alguna regla encuentra filas, indicando cuáles.
+func verificarIntegridad(tx *gorm.DB) error {
+	var problemas []string
+	for _, regla := range reglasAplicables(tx) {
+		var total int64
+		if err := tx.Raw(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", regla.Tabla, regla.Condicion)).
+			Scan(&total).Error; err != nil {
+			return err
+		}
+		if total > 0 {
+			problemas = append(problemas, fmt.Sprintf("%d %s", total, regla.Nombre))
+		}
+	}
+	if len(problemas) > 0 {
+		return fmt.Errorf("hay datos que violan las nuevas restricciones (%v); ejecuta \"repair\" antes de migrar", problemas)
+	}
+	return nil
+}
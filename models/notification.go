@@ -1,10 +1,25 @@
 package models
 
+// Estados de una notificación
+const (
+	NotificacionNoLeida = "unread"
+	NotificacionLeida   = "read"
+)
+
+// Tipos de evento que generan notificaciones
+const (
+	NotificacionCursoComprado      = "course_purchased"
+	NotificacionContrasenaCambiada = "password_changed"
+	NotificacionEmailCambiado      = "email_changed"
+	NotificacionResenaRespondida   = "review_replied"
+)
+
 type Notificación struct {
 	NotificationID string `gorm:"primaryKey;type:text" json:"notificationID"`
-	UserID         string `gorm:"not null;type:text" json:"userID"`
+	UserID         string `gorm:"not null;type:text;index:idx_notificacion_usuario" json:"userID"`
+	Type           string `gorm:"type:text" json:"type"`
 	Message        string `json:"message"`
-	Status         string `json:"status"`
+	Status         string `gorm:"index:idx_notificacion_usuario" json:"status"`
 	CreatedAt      string `json:"createdAt"` // Puedes usar un tipo de fecha si lo prefieres
 
 	User Usuario `gorm:"foreignKey:UserID"`
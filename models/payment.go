@@ -1,12 +1,30 @@
 package models
 
+// Estados posibles de un pago
+const (
+	EstadoPagoPendiente   = "pending"
+	EstadoPagoAprobado    = "approved"
+	EstadoPagoRechazado   = "rejected"
+	EstadoPagoReembolsado = "refunded"
+)
+
 type Pago struct {
 	PaymentID     string  `gorm:"primaryKey;type:text" json:"paymentID"`
 	UserID        string  `gorm:"not null;type:text" json:"userID"`
 	Amount        float64 `json:"amount"`
 	Status        string  `json:"status"`
 	PaymentMethod string  `json:"paymentMethod"`
-	PaymentDate   string  `json:"paymentDate"` // Puedes usar un tipo de fecha si lo prefieres
+	PaymentDate   string  `json:"paymentDate"`                       // Puedes usar un tipo de fecha si lo prefieres
+	GatewayRef    string  `gorm:"type:text;index" json:"gatewayRef"` // ID de la intención en la pasarela
+
+	User  Usuario    `gorm:"foreignKey:UserID"`
+	Items []PagoItem `gorm:"foreignKey:PaymentID" json:"items"`
+}
 
-	User Usuario `gorm:"foreignKey:UserID"`
+// PagoItem es un curso incluido en un pago, con el precio al momento de la compra.
+type PagoItem struct {
+	ItemID    string  `gorm:"primaryKey;type:text" json:"itemID"`
+	PaymentID string  `gorm:"not null;type:text;index" json:"paymentID"`
+	CourseID  string  `gorm:"not null;type:text" json:"courseID"`
+	Price     float64 `json:"price"`
 }
@@ -1,11 +1,15 @@
 package models
 
 type Reseña struct {
-	ReviewID string `gorm:"primaryKey;type:text" json:"reviewID"`
-	UserID   string `gorm:"not null;type:text" json:"userID"`
-	CourseID string `gorm:"not null;type:text" json:"courseID"`
-	Rating   int    `json:"rating"`
-	Comments string `json:"comments"`
+	ReviewID  string `gorm:"primaryKey;type:text" json:"reviewID"`
+	UserID    string `gorm:"not null;type:text;uniqueIndex:idx_resena_usuario_curso" json:"userID"`
+	CourseID  string `gorm:"not null;type:text;uniqueIndex:idx_resena_usuario_curso;index" json:"courseID"`
+	Rating    int    `json:"rating"`
+	Comments  string `json:"comments"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+	Reply     string `json:"reply"` // Respuesta del instructor, vacía si no hay
+	RepliedAt string `json:"repliedAt"`
 
 	User Usuario `gorm:"foreignKey:UserID"`
 }
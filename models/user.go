@@ -1,11 +1,19 @@
 package models
 
+// Roles posibles de un usuario. Los instructores son dueños de cursos y los
+// administradores tienen acceso a todas las operaciones.
+const (
+	RolUsuario    = "user"
+	RolInstructor = "instructor"
+	RolAdmin      = "admin"
+)
+
 type Usuario struct {
 	UserID       string `gorm:"primaryKey;column:user_id;type:text" json:"userID"`
 	NameLastName string `gorm:"column:name_last_name" json:"nameLastName"`
 	Username     string `gorm:"uniqueIndex;column:username" json:"username"`
 	Email        string `gorm:"uniqueIndex;column:email" json:"email"`
-	Password     string `gorm:"column:password" json:"password"`
+	Password     string `gorm:"column:password" json:"-"`
 	Role         string `gorm:"column:role" json:"role"`
 }
 
@@ -1,9 +1,19 @@
 package models
 
+// Orígenes posibles de una inscripción
+const (
+	InscripcionCompra = "purchase"
+	InscripcionRegalo = "gift"
+	InscripcionAdmin  = "admin"
+)
+
 type UsuarioCurso struct {
-	ID       string `gorm:"primaryKey;column:id;type:text;default:(hex(randomblob(16)))" json:"id"`
-	Email    string `gorm:"column:email;type:text" json:"email"` // Cambiado de Username a Email
-	CourseID string `gorm:"column:course_id;type:text" json:"courseID"`
+	ID         string  `gorm:"primaryKey;column:id;type:text" json:"id"`
+	UserID     string  `gorm:"column:user_id;type:text;not null" json:"userID"`
+	CourseID   string  `gorm:"column:course_id;type:text;not null" json:"courseID"`
+	EnrolledAt string  `gorm:"column:enrolled_at;not null" json:"enrolledAt"`
+	Source     string  `gorm:"column:source;not null" json:"source"`
+	PaymentID  *string `gorm:"column:payment_id;type:text" json:"paymentID"` // Solo en compras
 }
 
 // TableName especifica el nombre de la tabla en la base de datos.
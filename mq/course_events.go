@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"ProyectoIngeso/utils"
+
+	"github.com/streadway/amqp"
+)
+
+// Eventos que publica el servicio de cursos en el exchange courses_events.
+const (
+	ExchangeEventosCursos  = "courses_events"
+	EventoCursoActualizado = "course_updated"
+	EventoCursoEliminado   = "course_deleted"
+)
+
+// StartCourseEventsConsumer registra en gestor la escucha de los eventos del
+// servicio de cursos, que llama a alCambiarCurso con el courseID de cada
+// curso actualizado o eliminado. Cada instancia usa su propia cola
+// exclusiva para recibir todos los eventos del exchange; la cola se vuelve
+// a crear en cada reconexión.
+//
+// Los eventos publicados mientras no hay conexión se pierden; el caché de
+// cursos los compensa con su TTL.
+func StartCourseEventsConsumer(gestor *utils.GestorRabbitMQ, alCambiarCurso func(courseID string)) {
+	gestor.AlConectar(ExchangeEventosCursos, func(conn *amqp.Connection) error {
+		ch, err := conn.Channel()
+		if err != nil {
+			return fmt.Errorf("failed to open a channel: %w", err)
+		}
+
+		msgs, err := suscribirEventosCursos(ch)
+		if err != nil {
+			ch.Close()
+			return err
+		}
+
+		atender(conn, ch, ExchangeEventosCursos, msgs, func(d amqp.Delivery) {
+			var msg RabbitMQMessage
+			if err := json.Unmarshal(d.Body, &msg); err != nil {
+				log.Printf("Error unmarshalling course event: %s", err)
+				return
+			}
+
+			switch msg.Pattern {
+			case EventoCursoActualizado, EventoCursoEliminado:
+				alCambiarCurso(msg.Data)
+			default:
+				log.Printf("Evento de cursos no soportado: %s", msg.Pattern)
+			}
+		})
+		return nil
+	})
+}
+
+// suscribirEventosCursos declara el exchange de eventos de cursos y una cola
+// exclusiva enlazada a él, y empieza a consumirla.
+func suscribirEventosCursos(ch *amqp.Channel) (<-chan amqp.Delivery, error) {
+	err := ch.ExchangeDeclare(
+		ExchangeEventosCursos, // name
+		"fanout",              // type
+		true,                  // durable
+		false,                 // auto-deleted
+		false,                 // internal
+		false,                 // no-wait
+		nil,                   // arguments
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare an exchange: %w", err)
+	}
+
+	q, err := ch.QueueDeclare(
+		"",    // name
+		false, // durable
+		true,  // delete when unused
+		true,  // exclusive
+		false, // no-wait
+		nil,   // arguments
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare a queue: %w", err)
+	}
+
+	if err := ch.QueueBind(q.Name, "", ExchangeEventosCursos, false, nil); err != nil {
+		return nil, fmt.Errorf("failed to bind a queue: %w", err)
+	}
+
+	msgs, err := ch.Consume(
+		q.Name, // queue
+		"",     // consumer
+		true,   // auto-ack
+		true,   // exclusive
+		false,  // no-local
+		false,  // no-wait
+		nil,    // args
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register a consumer: %w", err)
+	}
+	return msgs, nil
+}
@@ -0,0 +1,260 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// MaxIntentosMensaje es la cantidad de veces que se procesa un mensaje antes
+// de mandarlo a la cola de mensajes muertos. Solo cuentan las fallas del
+// procesamiento; una reentrega del broker (tras una reconexión o un apagado
+// con mensajes sin empezar) se procesa como si fuera nueva.
+const MaxIntentosMensaje = 3
+
+// EsperaBaseReintento es la espera antes del primer reintento; se duplica en
+// cada intento siguiente.
+const EsperaBaseReintento = time.Second
+
+// encabezadoIntentos guarda en el mensaje cuántas veces se intentó procesar.
+const encabezadoIntentos = "x-intentos"
+
+// Códigos de las respuestas de error.
+const (
+	CodigoMensajeInvalido   = "invalid_message"
+	CodigoPatronDesconocido = "unknown_pattern"
+	CodigoNoEncontrado      = "not_found"
+	CodigoInterno           = "internal_error"
+	CodigoTiempoAgotado     = "timeout"
+)
+
+// ErrorMensaje es una falla al procesar un mensaje. Solo las reintentables
+// vuelven a la cola; las demás se responden de inmediato.
+type ErrorMensaje struct {
+	Codigo       string
+	Mensaje      string
+	Reintentable bool
+}
+
+func (e *ErrorMensaje) Error() string {
+	return e.Mensaje
+}
+
+// invalido indica si el mensaje en sí es el problema: no hay forma de
+// procesarlo y se guarda en la cola de mensajes muertos para revisarlo.
+func (e *ErrorMensaje) invalido() bool {
+	return e.Codigo == CodigoMensajeInvalido || e.Codigo == CodigoPatronDesconocido
+}
+
+func errorInterno(mensaje string, err error) *ErrorMensaje {
+	return &ErrorMensaje{
+		Codigo:       CodigoInterno,
+		Mensaje:      fmt.Sprintf("%s: %s", mensaje, err),
+		Reintentable: true,
+	}
+}
+
+// publicador es la parte de *amqp.Channel que usan las entregas para
+// responder y reencolar.
+type publicador interface {
+	Publish(exchange string, key string, mandatory bool, immediate bool, msg amqp.Publishing) error
+}
+
+// Respuesta es el sobre que recibe el ReplyTo de todo mensaje: Data si el
+// patrón se procesó, Error si falló.
+type Respuesta struct {
+	OK    bool            `json:"ok"`
+	Data  interface{}     `json:"data,omitempty"`
+	Error *ErrorRespuesta `json:"error,omitempty"`
+}
+
+// ErrorRespuesta describe la falla de un mensaje; Codigo es uno de los
+// códigos de error de este paquete.
+type ErrorRespuesta struct {
+	Codigo  string `json:"code"`
+	Mensaje string `json:"message"`
+}
+
+// entregar procesa una entrega de cola y decide su destino: confirmarla,
+// reencolarla con un intento más o mandarla a la cola de mensajes muertos.
+// procesar devuelve los datos de la respuesta o un error, preferentemente
+// *ErrorMensaje.
+func entregar(pub publicador, cola string, d amqp.Delivery, procesar func(msg RabbitMQMessage) (interface{}, error)) {
+	intentos := intentosPrevios(d)
+
+	var msg RabbitMQMessage
+	if err := json.Unmarshal(d.Body, &msg); err != nil {
+		fallar(pub, d, &ErrorMensaje{Codigo: CodigoMensajeInvalido, Mensaje: fmt.Sprintf("Error unmarshalling message: %s", err)})
+		return
+	}
+	// Sin el cuerpo: lleva datos personales como emails
+	log.Printf("Mensaje %s recibido en %s: %s", d.CorrelationId, cola, msg.Pattern)
+
+	respuesta, err := procesar(msg)
+	if err == nil {
+		var cuerpo []byte
+		if cuerpo, err = json.Marshal(Respuesta{OK: true, Data: respuesta}); err == nil {
+			responder(pub, d, cuerpo)
+			confirmar(d)
+			return
+		}
+		err = &ErrorMensaje{Codigo: CodigoInterno, Mensaje: fmt.Sprintf("Error marshalling response: %s", err)}
+	}
+
+	var errMensaje *ErrorMensaje
+	if !errors.As(err, &errMensaje) {
+		errMensaje = errorInterno("Error al procesar el mensaje", err)
+	}
+	if errMensaje.Reintentable {
+		reintentar(pub, cola, d, intentos+1, errMensaje)
+		return
+	}
+	fallar(pub, d, errMensaje)
+}
+
+// reintentar publica el mensaje en la cola de espera del intento, que lo
+// devuelve a cola al vencer su TTL, con el contador de intentos actualizado,
+// y confirma la entrega original. Agotados los intentos, el mensaje falla de
+// forma definitiva.
+func reintentar(pub publicador, cola string, d amqp.Delivery, intentos int, causa *ErrorMensaje) {
+	log.Printf("Intento %d de %d fallido para el mensaje %s: %s", intentos, MaxIntentosMensaje, d.CorrelationId, causa.Mensaje)
+	if intentos >= MaxIntentosMensaje {
+		fallar(pub, d, &ErrorMensaje{Codigo: causa.Codigo, Mensaje: causa.Mensaje})
+		return
+	}
+
+	encabezados := amqp.Table{}
+	for clave, valor := range d.Headers {
+		encabezados[clave] = valor
+	}
+	encabezados[encabezadoIntentos] = int32(intentos)
+
+	err := pub.Publish("", colaReintento(cola, intentos), false, false, amqp.Publishing{
+		Headers:       encabezados,
+		ContentType:   d.ContentType,
+		DeliveryMode:  amqp.Persistent,
+		CorrelationId: d.CorrelationId,
+		ReplyTo:       d.ReplyTo,
+		MessageId:     d.MessageId,
+		Body:          d.Body,
+	})
+	if err != nil {
+		// Sin la copia, el broker lo reentregará y se contará como otro intento
+		log.Printf("No se pudo reencolar el mensaje %s: %s", d.CorrelationId, err)
+		if err := d.Nack(false, true); err != nil {
+			log.Printf("Failed to nack a message: %s", err)
+		}
+		return
+	}
+	confirmar(d)
+}
+
+// fallar responde el error al ReplyTo. Un mensaje inválido o que agotó sus
+// intentos va a la cola de mensajes muertos; los demás se confirman, porque
+// el error es la respuesta.
+func fallar(pub publicador, d amqp.Delivery, causa *ErrorMensaje) {
+	log.Printf("Mensaje %s fallido (%s): %s", d.CorrelationId, causa.Codigo, causa.Mensaje)
+
+	cuerpo, err := json.Marshal(Respuesta{Error: &ErrorRespuesta{Codigo: causa.Codigo, Mensaje: causa.Mensaje}})
+	if err == nil {
+		responder(pub, d, cuerpo)
+	}
+
+	if causa.invalido() || causa.Codigo == CodigoInterno || causa.Codigo == CodigoTiempoAgotado {
+		if err := d.Nack(false, false); err != nil {
+			log.Printf("Failed to nack a message: %s", err)
+		}
+		return
+	}
+	confirmar(d)
+}
+
+// responder publica cuerpo en la cola ReplyTo del mensaje, si la indicó.
+func responder(pub publicador, d amqp.Delivery, cuerpo []byte) {
+	if d.ReplyTo == "" {
+		return
+	}
+	err := pub.Publish(
+		"",        // exchange
+		d.ReplyTo, // routing key
+		false,     // mandatory
+		false,     // immediate
+		amqp.Publishing{
+			ContentType:   "application/json",
+			CorrelationId: d.CorrelationId,
+			Body:          cuerpo,
+		})
+	if err != nil {
+		log.Printf("Failed to publish a response: %s", err)
+	}
+}
+
+func confirmar(d amqp.Delivery) {
+	if err := d.Ack(false); err != nil {
+		log.Printf("Failed to ack a message: %s", err)
+	}
+}
+
+// intentosPrevios lee el contador de intentos del mensaje; cero si es nuevo.
+func intentosPrevios(d amqp.Delivery) int {
+	switch n := d.Headers[encabezadoIntentos].(type) {
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	case int:
+		return n
+	}
+	return 0
+}
+
+// atender pasa cada entrega de msgs a manejar en una goroutine propia del
+// consumidor. Si el canal se cierra solo, con la conexión todavía abierta,
+// se cierra también la conexión para que el gestor reconecte y vuelva a
+// suscribir a todos los consumidores.
+func atender(conn *amqp.Connection, ch *amqp.Channel, nombre string, msgs <-chan amqp.Delivery, manejar func(d amqp.Delivery)) {
+	go func() {
+		defer ch.Close()
+		for d := range msgs {
+			manejar(d)
+		}
+		if !conn.IsClosed() {
+			log.Printf("El consumidor %s se detuvo; se reinicia la conexión con RabbitMQ", nombre)
+			conn.Close()
+		}
+	}()
+}
+
+// colaReintento es la cola donde espera un mensaje tras su intento fallido
+// número intento.
+func colaReintento(cola string, intento int) string {
+	return fmt.Sprintf("%s.retry.%d", cola, intento)
+}
+
+// declararColasReintento declara una cola de espera por cada reintento
+// posible de cola. No tienen consumidores: al vencer el TTL, que se duplica
+// en cada intento, el broker devuelve el mensaje a cola.
+func declararColasReintento(ch *amqp.Channel, cola string) error {
+	for intento := 1; intento < MaxIntentosMensaje; intento++ {
+		espera := EsperaBaseReintento << (intento - 1)
+		if _, err := ch.QueueDeclare(
+			colaReintento(cola, intento), // name
+			true,                         // durable
+			false,                        // delete when unused
+			false,                        // exclusive
+			false,                        // no-wait
+			amqp.Table{
+				"x-message-ttl":             int32(espera / time.Millisecond),
+				"x-dead-letter-exchange":    "",
+				"x-dead-letter-routing-key": cola,
+			},
+		); err != nil {
+			return fmt.Errorf("failed to declare a queue: %w", err)
+		}
+	}
+	return nil
+}
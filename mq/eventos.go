@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"ProyectoIngeso/events"
+	"ProyectoIngeso/utils"
+
+	"github.com/streadway/amqp"
+)
+
+// ExchangeEventosUsuarios es el exchange topic donde se publican los eventos
+// de dominio del servicio de usuarios, con el tipo de evento como routing
+// key. Los demás servicios enlazan sus colas con el patrón que les
+// interesa, por ejemplo "user.*" o "enrollment.created".
+const ExchangeEventosUsuarios = "users.events"
+
+// errSinConexion indica que no hay conexión con RabbitMQ para publicar.
+var errSinConexion = errors.New("sin conexión con RabbitMQ")
+
+// PublicadorEventos publica eventos de dominio en ExchangeEventosUsuarios.
+// Implementa events.Publisher.
+//
+// Los eventos se publican cuando la operación ya se confirmó; si en ese
+// momento no hay conexión con el broker, el evento se pierde.
+type PublicadorEventos struct {
+	mu    sync.RWMutex
+	canal *amqp.Channel
+}
+
+// NuevoPublicadorEventos registra en gestor la declaración del exchange y
+// un canal de publicación propio, que se renueva en cada reconexión.
+func NuevoPublicadorEventos(gestor *utils.GestorRabbitMQ) *PublicadorEventos {
+	p := &PublicadorEventos{}
+	gestor.AlConectar(ExchangeEventosUsuarios, func(conn *amqp.Connection) error {
+		ch, err := conn.Channel()
+		if err != nil {
+			return fmt.Errorf("failed to open a channel: %w", err)
+		}
+
+		err = ch.ExchangeDeclare(
+			ExchangeEventosUsuarios, // name
+			"topic",                 // type
+			true,                    // durable
+			false,                   // auto-deleted
+			false,                   // internal
+			false,                   // no-wait
+			nil,                     // arguments
+		)
+		if err != nil {
+			ch.Close()
+			return fmt.Errorf("failed to declare an exchange: %w", err)
+		}
+
+		p.mu.Lock()
+		p.canal = ch
+		p.mu.Unlock()
+
+		// Dejar de publicar por el canal en cuanto se cierre
+		cerrado := ch.NotifyClose(make(chan *amqp.Error, 1))
+		go func() {
+			<-cerrado
+			p.mu.Lock()
+			if p.canal == ch {
+				p.canal = nil
+			}
+			p.mu.Unlock()
+		}()
+		return nil
+	})
+	return p
+}
+
+// Publish publica el evento como mensaje persistente.
+func (p *PublicadorEventos) Publish(ctx context.Context, evento events.Event) error {
+	p.mu.RLock()
+	ch := p.canal
+	p.mu.RUnlock()
+	if ch == nil {
+		return errSinConexion
+	}
+
+	cuerpo, err := json.Marshal(evento)
+	if err != nil {
+		return fmt.Errorf("error al serializar el evento %s: %w", evento.Type, err)
+	}
+
+	return ch.Publish(
+		ExchangeEventosUsuarios, // exchange
+		evento.Type,             // routing key
+		false,                   // mandatory
+		false,                   // immediate
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			MessageId:    evento.ID,
+			Type:         evento.Type,
+			Timestamp:    time.Now().UTC(),
+			Headers:      amqp.Table{"x-version": int32(evento.Version)},
+			Body:         cuerpo,
+		})
+}
@@ -0,0 +1,183 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Manejador atiende un patrón RPC. data es el campo Data del mensaje; lo
+// que devuelve va en el campo data de la Respuesta. Un error que no sea
+// *ErrorMensaje se trata como error interno y el mensaje se reintenta.
+//
+// ctx vence cuando se agota el tiempo del patrón y el manejador debe
+// respetarlo: pasarlo a toda consulta y abandonar el trabajo al vencer. Un
+// mensaje que agota su tiempo no se reintenta, porque el manejador pudo
+// haber completado la operación de todos modos.
+type Manejador func(ctx context.Context, data string) (interface{}, error)
+
+// RegistroRPC asocia cada patrón con su manejador. Los patrones se registran
+// al iniciar el servicio, antes de empezar a consumir.
+type RegistroRPC struct {
+	esperaPorDefecto time.Duration
+
+	mu       sync.RWMutex
+	patrones map[string]*patronRPC
+}
+
+type patronRPC struct {
+	manejar  Manejador
+	espera   time.Duration
+	metricas contadoresPatron
+}
+
+// NuevoRegistroRPC crea un registro vacío. esperaPorDefecto es el tiempo
+// máximo de los patrones registrados con Register.
+func NuevoRegistroRPC(esperaPorDefecto time.Duration) *RegistroRPC {
+	return &RegistroRPC{
+		esperaPorDefecto: esperaPorDefecto,
+		patrones:         make(map[string]*patronRPC),
+	}
+}
+
+// Register asocia pattern con handler, con el tiempo máximo por defecto del
+// registro. Registrar dos veces el mismo patrón es un error de programación.
+func (r *RegistroRPC) Register(pattern string, handler Manejador) {
+	r.RegisterConEspera(pattern, r.esperaPorDefecto, handler)
+}
+
+// RegisterConEspera es como Register, con un tiempo máximo propio del
+// patrón.
+func (r *RegistroRPC) RegisterConEspera(pattern string, espera time.Duration, handler Manejador) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.patrones[pattern]; ok {
+		panic(fmt.Sprintf("patrón RPC registrado dos veces: %s", pattern))
+	}
+	r.patrones[pattern] = &patronRPC{manejar: handler, espera: espera}
+}
+
+// procesar despacha msg a su manejador con el tiempo máximo del patrón. El
+// manejador corre en su propia goroutine para no retener al trabajador si
+// no respeta ctx; un pánico se trata como error interno.
+func (r *RegistroRPC) procesar(msg RabbitMQMessage) (interface{}, error) {
+	r.mu.RLock()
+	patron, ok := r.patrones[msg.Pattern]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, &ErrorMensaje{
+			Codigo:  CodigoPatronDesconocido,
+			Mensaje: fmt.Sprintf("Patrón no soportado: %s", msg.Pattern),
+		}
+	}
+
+	ctx, cancelar := context.WithTimeout(context.Background(), patron.espera)
+	defer cancelar()
+
+	type resultado struct {
+		respuesta interface{}
+		err       error
+	}
+	hecho := make(chan resultado, 1)
+	inicio := time.Now()
+	go func() {
+		respuesta, err := llamarSinPanico(ctx, patron.manejar, msg.Data)
+		hecho <- resultado{respuesta, err}
+	}()
+
+	select {
+	case res := <-hecho:
+		patron.metricas.registrar(time.Since(inicio), res.err, false)
+		return res.respuesta, res.err
+	case <-ctx.Done():
+		patron.metricas.registrar(time.Since(inicio), ctx.Err(), true)
+		// No se reintenta: el manejador puede seguir corriendo y completar la
+		// operación, que se ejecutaría dos veces
+		return nil, &ErrorMensaje{
+			Codigo:  CodigoTiempoAgotado,
+			Mensaje: fmt.Sprintf("El patrón %s no respondió en %s", msg.Pattern, patron.espera),
+		}
+	}
+}
+
+func llamarSinPanico(ctx context.Context, manejar Manejador, data string) (respuesta interface{}, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = errorInterno("Pánico al procesar el mensaje", fmt.Errorf("%v", p))
+		}
+	}()
+	return manejar(ctx, data)
+}
+
+// MetricasPatron resume las llamadas a un patrón desde que inició el
+// servicio. Cuenta cada intento, incluidos los reintentos.
+type MetricasPatron struct {
+	Llamadas        int64   `json:"calls"`
+	Errores         int64   `json:"errors"`
+	NoEncontrados   int64   `json:"not_found"`
+	TiemposAgotados int64   `json:"timeouts"`
+	DuracionMedia   float64 `json:"avg_ms"`
+	DuracionMaxima  float64 `json:"max_ms"`
+}
+
+// Metricas devuelve las métricas de cada patrón registrado.
+func (r *RegistroRPC) Metricas() map[string]MetricasPatron {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	metricas := make(map[string]MetricasPatron, len(r.patrones))
+	for nombre, patron := range r.patrones {
+		metricas[nombre] = patron.metricas.leer()
+	}
+	return metricas
+}
+
+type contadoresPatron struct {
+	mu              sync.Mutex
+	llamadas        int64
+	errores         int64
+	noEncontrados   int64
+	tiemposAgotados int64
+	duracionTotal   time.Duration
+	duracionMaxima  time.Duration
+}
+
+func (c *contadoresPatron) registrar(duracion time.Duration, err error, tiempoAgotado bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.llamadas++
+	c.duracionTotal += duracion
+	c.duracionMaxima = max(c.duracionMaxima, duracion)
+
+	var errMensaje *ErrorMensaje
+	switch {
+	case tiempoAgotado:
+		c.tiemposAgotados++
+	case errors.As(err, &errMensaje) && errMensaje.Codigo == CodigoNoEncontrado:
+		// Un recurso inexistente es una respuesta válida, no una falla
+		c.noEncontrados++
+	case err != nil:
+		c.errores++
+	}
+}
+
+func (c *contadoresPatron) leer() MetricasPatron {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	metricas := MetricasPatron{
+		Llamadas:        c.llamadas,
+		Errores:         c.errores,
+		NoEncontrados:   c.noEncontrados,
+		TiemposAgotados: c.tiemposAgotados,
+		DuracionMaxima:  milisegundos(c.duracionMaxima),
+	}
+	if c.llamadas > 0 {
+		metricas.DuracionMedia = milisegundos(c.duracionTotal / time.Duration(c.llamadas))
+	}
+	return metricas
+}
+
+func milisegundos(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/streadway/amqp"
+)
+
+// OpcionesTrabajadores configura el consumo concurrente de una cola.
+type OpcionesTrabajadores struct {
+	// Trabajadores es la cantidad de mensajes que se procesan a la vez.
+	Trabajadores int
+	// Prefetch es la cantidad de mensajes sin confirmar que el broker
+	// entrega al consumidor; conviene que sea al menos Trabajadores.
+	Prefetch int
+}
+
+// grupoTrabajadores reparte las entregas de una cola entre varios
+// trabajadores. Consume por un canal y publica respuestas y reintentos por
+// otro propio, para que una falla al publicar no cierre el canal de consumo.
+type grupoTrabajadores struct {
+	cola     string
+	opciones OpcionesTrabajadores
+
+	mu       sync.Mutex
+	detenido bool
+	consumo  *amqp.Channel // Canal de la suscripción actual
+	activos  sync.WaitGroup
+}
+
+func nuevoGrupoTrabajadores(cola string, opciones OpcionesTrabajadores) *grupoTrabajadores {
+	opciones.Trabajadores = max(opciones.Trabajadores, 1)
+	opciones.Prefetch = max(opciones.Prefetch, opciones.Trabajadores)
+	return &grupoTrabajadores{cola: cola, opciones: opciones}
+}
+
+// suscribir abre los canales en conn, declara la cola con declarar y
+// arranca los trabajadores, que pasan cada entrega a manejar. Si el consumo
+// se corta con la conexión abierta, se cierra la conexión para que el
+// gestor reconecte y vuelva a suscribir.
+func (g *grupoTrabajadores) suscribir(conn *amqp.Connection, declarar func(ch *amqp.Channel) error, manejar func(pub publicador, d amqp.Delivery)) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.detenido {
+		return nil
+	}
+
+	consumo, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open a channel: %w", err)
+	}
+	publicacion, err := conn.Channel()
+	if err != nil {
+		consumo.Close()
+		return fmt.Errorf("failed to open a channel: %w", err)
+	}
+	cerrar := func() {
+		publicacion.Close()
+		consumo.Close()
+	}
+
+	if err := consumo.Qos(g.opciones.Prefetch, 0, false); err != nil {
+		cerrar()
+		return fmt.Errorf("failed to set QoS: %w", err)
+	}
+	if err := declarar(consumo); err != nil {
+		cerrar()
+		return err
+	}
+
+	msgs, err := consumo.Consume(
+		g.cola, // queue
+		g.cola, // consumer
+		false,  // auto-ack
+		false,  // exclusive
+		false,  // no-local
+		false,  // no-wait
+		nil,    // args
+	)
+	if err != nil {
+		cerrar()
+		return fmt.Errorf("failed to register a consumer: %w", err)
+	}
+	g.consumo = consumo
+
+	var suscripcion sync.WaitGroup
+	suscripcion.Add(g.opciones.Trabajadores)
+	g.activos.Add(g.opciones.Trabajadores)
+	for range g.opciones.Trabajadores {
+		go func() {
+			defer g.activos.Done()
+			defer suscripcion.Done()
+			for d := range msgs {
+				manejar(publicacion, d)
+			}
+		}()
+	}
+
+	go func() {
+		suscripcion.Wait()
+		cerrar()
+
+		g.mu.Lock()
+		detenido := g.detenido
+		g.mu.Unlock()
+		if !detenido && !conn.IsClosed() {
+			log.Printf("El consumidor %s se detuvo; se reinicia la conexión con RabbitMQ", g.cola)
+			conn.Close()
+		}
+	}()
+
+	log.Printf("Esperando mensajes en %s con %d trabajadores.", g.cola, g.opciones.Trabajadores)
+	return nil
+}
+
+// drenar deja de recibir mensajes y espera a que los trabajadores terminen
+// los que están procesando, o a que ctx se cancele. Los mensajes que el
+// broker ya había entregado y nadie empezó vuelven a la cola al cerrarse el
+// canal.
+func (g *grupoTrabajadores) drenar(ctx context.Context) error {
+	g.mu.Lock()
+	g.detenido = true
+	if g.consumo != nil {
+		if err := g.consumo.Cancel(g.cola, false); err != nil && err != amqp.ErrClosed {
+			log.Printf("No se pudo cancelar el consumidor %s: %s", g.cola, err)
+		}
+	}
+	g.mu.Unlock()
+
+	terminados := make(chan struct{})
+	go func() {
+		g.activos.Wait()
+		close(terminados)
+	}()
+
+	select {
+	case <-terminados:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%s: quedaron mensajes en proceso: %w", g.cola, ctx.Err())
+	}
+}
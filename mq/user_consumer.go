@@ -1,168 +1,164 @@
 package utils
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"log"
 
-	"ProyectoIngeso/models"
+	"ProyectoIngeso/services"
 	"ProyectoIngeso/utils"
 
 	"github.com/streadway/amqp"
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
 )
 
 // Definir la estructura que corresponde al mensaje recibido desde RabbitMQ
 type RabbitMQMessage struct {
-	Pattern string `json:"pattern"` // "get_user_id", "get_user_name", "get_cart_courses", "clear_user_cart"
+	Pattern string `json:"pattern"` // Patrón registrado en el RegistroRPC, por ejemplo "get_user_id"
 	Data    string `json:"data"`    // Email, userID, etc.
 	ID      string `json:"id"`
 }
 
-// Iniciar el consumidor de RabbitMQ desde main.go
-func StartUserConsumer() error {
-	// Conectar a RabbitMQ
-	conn, ch, err := utils.ConnectRabbitMQ()
-	if err != nil {
-		return fmt.Errorf("error connecting to RabbitMQ: %w", err)
-	}
-	defer conn.Close()
-	defer ch.Close()
+// Topología de la cola de solicitudes de usuarios. Los mensajes que no se
+// pueden procesar terminan en ColaUsuariosMuertos a través de
+// ExchangeUsuariosMuertos, para revisarlos sin que bloqueen la cola. Los que
+// fallan por un error transitorio esperan en users_queue.retry.N antes de
+// volver a la cola.
+//
+// Una cola users_queue creada por una versión anterior (no durable y sin
+// dead-letter) no acepta la nueva declaración; hay que borrarla una vez
+// desde la consola de RabbitMQ antes de desplegar.
+const (
+	ColaUsuarios            = "users_queue"
+	ExchangeUsuariosMuertos = "users_queue.dlx"
+	ColaUsuariosMuertos     = "users_queue.dead"
+)
 
-	// Conectar a la base de datos
-	db, err := gorm.Open(sqlite.Open("base_datos.db"), &gorm.Config{})
-	if err != nil {
-		return fmt.Errorf("error connecting to database: %w", err)
-	}
+// ConsumidorUsuarios atiende las solicitudes de users_queue.
+type ConsumidorUsuarios struct {
+	grupo *grupoTrabajadores
+}
 
-	// Declarar la cola para escuchar las solicitudes
-	queueName := "users_queue"
-	q, err := ch.QueueDeclare(
-		queueName, // name
-		false,     // durable
-		false,     // delete when unused
-		false,     // exclusive
-		false,     // no-wait
-		nil,       // arguments
+// Registrar el consumidor de RabbitMQ desde main.go; gestor lo vuelve a
+// suscribir en cada reconexión. Cada mensaje se despacha al manejador de su
+// patrón en registro y la respuesta llega al ReplyTo dentro de una
+// Respuesta.
+//
+// Los mensajes se reparten entre opciones.Trabajadores goroutines. Cada uno
+// se confirma solo después de procesarlo, así que una caída a mitad del
+// procesamiento no lo pierde.
+func StartUserConsumer(gestor *utils.GestorRabbitMQ, registro *RegistroRPC, opciones OpcionesTrabajadores) *ConsumidorUsuarios {
+	consumidor := &ConsumidorUsuarios{grupo: nuevoGrupoTrabajadores(ColaUsuarios, opciones)}
+	gestor.AlConectar(ColaUsuarios, func(conn *amqp.Connection) error {
+		return consumidor.grupo.suscribir(conn, declararColaUsuarios, func(pub publicador, d amqp.Delivery) {
+			entregar(pub, ColaUsuarios, d, registro.procesar)
+		})
+	})
+	return consumidor
+}
+
+// Drenar deja de recibir solicitudes y espera a que terminen las que están
+// en proceso, o a que ctx se cancele. Se llama al apagar el servidor, antes
+// de cerrar la conexión con RabbitMQ.
+func (c *ConsumidorUsuarios) Drenar(ctx context.Context) error {
+	return c.grupo.drenar(ctx)
+}
+
+// declararColaUsuarios declara la cola durable de solicitudes junto con su
+// exchange y cola de mensajes muertos y sus colas de reintento.
+func declararColaUsuarios(ch *amqp.Channel) error {
+	err := ch.ExchangeDeclare(
+		ExchangeUsuariosMuertos, // name
+		"fanout",                // type
+		true,                    // durable
+		false,                   // auto-deleted
+		false,                   // internal
+		false,                   // no-wait
+		nil,                     // arguments
 	)
 	if err != nil {
+		return fmt.Errorf("failed to declare an exchange: %w", err)
+	}
+
+	if _, err := ch.QueueDeclare(
+		ColaUsuariosMuertos, // name
+		true,                // durable
+		false,               // delete when unused
+		false,               // exclusive
+		false,               // no-wait
+		nil,                 // arguments
+	); err != nil {
 		return fmt.Errorf("failed to declare a queue: %w", err)
 	}
+	if err := ch.QueueBind(ColaUsuariosMuertos, "", ExchangeUsuariosMuertos, false, nil); err != nil {
+		return fmt.Errorf("failed to bind a queue: %w", err)
+	}
 
-	msgs, err := ch.Consume(
-		q.Name, // queue
-		"",     // consumer
-		true,   // auto-ack
-		false,  // exclusive
-		false,  // no-local
-		false,  // no-wait
-		nil,    // args
-	)
-	if err != nil {
-		return fmt.Errorf("failed to register a consumer: %w", err)
+	if _, err := ch.QueueDeclare(
+		ColaUsuarios, // name
+		true,         // durable
+		false,        // delete when unused
+		false,        // exclusive
+		false,        // no-wait
+		amqp.Table{"x-dead-letter-exchange": ExchangeUsuariosMuertos},
+	); err != nil {
+		return fmt.Errorf("failed to declare a queue: %w", err)
 	}
+	return declararColasReintento(ch, ColaUsuarios)
+}
 
-	go func() {
-		for d := range msgs {
-			fmt.Printf("Mensaje recibido: %s\n", string(d.Body))
-
-			var msg RabbitMQMessage
-			err := json.Unmarshal(d.Body, &msg)
-			if err != nil {
-				log.Printf("Error unmarshalling message: %s", err)
-				continue
-			}
-
-			var responseBody []byte
-
-			switch msg.Pattern {
-			case "get_user_id":
-				var usuario models.Usuario
-				result := db.Where("email = ?", msg.Data).First(&usuario)
-				if result.Error != nil {
-					log.Printf("No se encontró un usuario con el correo %s: %s", msg.Data, result.Error)
-					continue
-				}
-				response := struct {
-					UserID string `json:"userID"`
-				}{UserID: usuario.UserID}
-				responseBody, err = json.Marshal(response)
-				if err != nil {
-					log.Printf("Error marshalling response: %s", err)
-					continue
-				}
-
-			case "get_user_name":
-				var usuario models.Usuario
-				result := db.Where("user_id = ?", msg.Data).First(&usuario)
-				if result.Error != nil {
-					log.Printf("No se encontró un usuario con ID %s: %s", msg.Data, result.Error)
-					continue
-				}
-				response := struct {
-					Name string `json:"name"`
-				}{Name: usuario.NameLastName}
-				responseBody, err = json.Marshal(response)
-				if err != nil {
-					log.Printf("Error marshalling response: %s", err)
-					continue
-				}
-
-			case "get_cart_courses":
-				var carritos []models.Carrito
-				result := db.Where("user_id = ?", msg.Data).Find(&carritos)
-				if result.Error != nil {
-					log.Printf("Error al obtener el carrito para el usuario %s: %s", msg.Data, result.Error)
-					continue
-				}
-				responseBody, err = json.Marshal(carritos)
-				if err != nil {
-					log.Printf("Error al serializar la respuesta: %s", err)
-					continue
-				}
-
-			case "clear_user_cart":
-				// Vaciar el carrito del usuario
-				userID := msg.Data
-				result := db.Where("user_id = ?", userID).Delete(&models.Carrito{})
-				if result.Error != nil {
-					log.Printf("Error al vaciar el carrito para el usuario %s: %s", userID, result.Error)
-					continue
-				}
-				log.Printf("Carrito vaciado para el usuario %s", userID)
-				response := struct {
-					Message string `json:"message"`
-				}{Message: "Carrito vaciado exitosamente"}
-				responseBody, err = json.Marshal(response)
-				if err != nil {
-					log.Printf("Error al serializar la respuesta: %s", err)
-					continue
-				}
-
-			default:
-				log.Printf("Patrón no soportado: %s", msg.Pattern)
-				continue
-			}
-
-			err = ch.Publish(
-				"",        // exchange
-				d.ReplyTo, // routing key
-				false,     // mandatory
-				false,     // immediate
-				amqp.Publishing{
-					ContentType:   "application/json",
-					CorrelationId: d.CorrelationId,
-					Body:          responseBody,
-				})
-			if err != nil {
-				log.Printf("Failed to publish a response: %s", err)
-			}
+// RegistrarPatronesUsuarios registra los patrones que atiende el servicio de
+// usuarios con los mismos servicios que el servidor GraphQL.
+// alVaciarCarrito, si no es nil, se invoca después de procesar
+// clear_user_cart para avisar a los clientes suscritos.
+func RegistrarPatronesUsuarios(registro *RegistroRPC, servicios services.Servicios, alVaciarCarrito func(userID string)) {
+	registro.Register("get_user_id", func(ctx context.Context, email string) (interface{}, error) {
+		usuario, err := servicios.Usuarios.PorEmail(ctx, email)
+		if err != nil {
+			return nil, errorUsuario(err, fmt.Sprintf("No se encontró un usuario con el correo %s", email))
+		}
+		return struct {
+			UserID string `json:"userID"`
+		}{UserID: usuario.UserID}, nil
+	})
+
+	registro.Register("get_user_name", func(ctx context.Context, userID string) (interface{}, error) {
+		usuario, err := servicios.Usuarios.PorID(ctx, userID)
+		if err != nil {
+			return nil, errorUsuario(err, fmt.Sprintf("No se encontró un usuario con ID %s", userID))
 		}
-	}()
+		return struct {
+			Name string `json:"name"`
+		}{Name: usuario.NameLastName}, nil
+	})
+
+	registro.Register("get_cart_courses", func(ctx context.Context, userID string) (interface{}, error) {
+		carritos, err := servicios.Carritos.Items(ctx, userID)
+		if err != nil {
+			return nil, errorInterno(fmt.Sprintf("Error al obtener el carrito para el usuario %s", userID), err)
+		}
+		return carritos, nil
+	})
 
-	log.Printf("Esperando mensajes. Presiona CTRL+C para salir.")
-	<-make(chan bool)
+	registro.Register("clear_user_cart", func(ctx context.Context, userID string) (interface{}, error) {
+		if err := servicios.Carritos.Vaciar(ctx, userID); err != nil {
+			return nil, errorInterno(fmt.Sprintf("Error al vaciar el carrito para el usuario %s", userID), err)
+		}
+		log.Printf("Carrito vaciado para el usuario %s", userID)
+		if alVaciarCarrito != nil {
+			alVaciarCarrito(userID)
+		}
+		return struct {
+			Message string `json:"message"`
+		}{Message: "Carrito vaciado exitosamente"}, nil
+	})
+}
 
-	return nil
+// errorUsuario distingue un usuario inexistente, que es una respuesta
+// válida, de una falla al consultarlo.
+func errorUsuario(err error, mensaje string) error {
+	if errors.Is(err, services.ErrUsuarioNoEncontrado) {
+		return &ErrorMensaje{Codigo: CodigoNoEncontrado, Mensaje: mensaje}
+	}
+	return errorInterno(mensaje, err)
 }
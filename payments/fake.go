@@ -0,0 +1,142 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Tokens de tarjeta con resultado conocido en la pasarela falsa. Cualquier
+// otro token se aprueba mientras el monto no supere LimiteFake. Con
+// TokenFallaPasarela la pasarela no llega a crear la intención.
+const (
+	TokenRechazado           = "tok_declined"
+	TokenFondosInsuficientes = "tok_insufficient_funds"
+	TokenFallaPasarela       = "tok_gateway_error"
+	LimiteFake               = 1000.0
+)
+
+// FakeGateway es una pasarela en memoria que aprueba o rechaza de forma
+// determinista según el token y el monto. Sirve para recorrer el ciclo de
+// vida completo de un pago sin acceso a la red.
+type FakeGateway struct {
+	secreto []byte
+
+	mu         sync.Mutex
+	intents    map[string]*Intent
+	reembolsos map[string]*Refund // Por intentID
+}
+
+// NewFakeGateway crea la pasarela falsa. secreto firma y verifica los webhooks.
+func NewFakeGateway(secreto string) *FakeGateway {
+	return &FakeGateway{
+		secreto:    []byte(secreto),
+		intents:    make(map[string]*Intent),
+		reembolsos: make(map[string]*Refund),
+	}
+}
+
+func (g *FakeGateway) CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error) {
+	if req.Amount <= 0 {
+		return nil, errors.New("el monto debe ser mayor que cero")
+	}
+	if req.CardToken == TokenFallaPasarela {
+		return nil, errors.New("la pasarela no está disponible")
+	}
+
+	intent := &Intent{
+		ID:        "pi_" + uuid.NewString(),
+		Amount:    req.Amount,
+		Currency:  req.Currency,
+		Status:    IntentPendienteCaptura,
+		Reference: req.Reference,
+	}
+
+	switch {
+	case req.CardToken == TokenRechazado:
+		intent.Status = IntentRechazada
+		intent.DeclineReason = "tarjeta rechazada"
+	case req.CardToken == TokenFondosInsuficientes:
+		intent.Status = IntentRechazada
+		intent.DeclineReason = "fondos insuficientes"
+	case req.Amount > LimiteFake:
+		intent.Status = IntentRechazada
+		intent.DeclineReason = fmt.Sprintf("el monto supera el límite de %.2f", LimiteFake)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.intents[intent.ID] = intent
+
+	copia := *intent
+	return &copia, nil
+}
+
+func (g *FakeGateway) Capture(ctx context.Context, intentID string) (*Intent, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	intent, ok := g.intents[intentID]
+	if !ok {
+		return nil, ErrIntentNoEncontrada
+	}
+	if intent.Status != IntentPendienteCaptura {
+		return nil, fmt.Errorf("no se puede capturar una intención en estado %s", intent.Status)
+	}
+	intent.Status = IntentCapturada
+
+	copia := *intent
+	return &copia, nil
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, intentID string, amount float64) (*Refund, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	intent, ok := g.intents[intentID]
+	if !ok {
+		return nil, ErrIntentNoEncontrada
+	}
+	if reembolso, ok := g.reembolsos[intentID]; ok {
+		return reembolso, nil
+	}
+	if intent.Status != IntentCapturada {
+		return nil, fmt.Errorf("no se puede reembolsar una intención en estado %s", intent.Status)
+	}
+	if amount <= 0 || amount > intent.Amount {
+		return nil, errors.New("monto de reembolso inválido")
+	}
+	intent.Status = IntentReembolsada
+
+	reembolso := &Refund{ID: "re_" + uuid.NewString(), IntentID: intentID, Amount: amount}
+	g.reembolsos[intentID] = reembolso
+	return reembolso, nil
+}
+
+func (g *FakeGateway) VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error) {
+	esperada := g.Firmar(payload)
+	if !hmac.Equal([]byte(esperada), []byte(signature)) {
+		return nil, ErrFirmaInvalida
+	}
+
+	var evento WebhookEvent
+	if err := json.Unmarshal(payload, &evento); err != nil {
+		return nil, fmt.Errorf("webhook con formato inválido: %w", err)
+	}
+	return &evento, nil
+}
+
+// Firmar calcula la firma HMAC-SHA256 (hex) que espera VerifyWebhook. Permite
+// simular webhooks del proveedor en desarrollo.
+func (g *FakeGateway) Firmar(payload []byte) string {
+	mac := hmac.New(sha256.New, g.secreto)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
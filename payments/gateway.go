@@ -0,0 +1,94 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Proveedores de pago reconocidos por New.
+const (
+	// ProveedorFake es la pasarela en memoria; aprueba casi cualquier
+	// tarjeta, así que solo sirve para desarrollo.
+	ProveedorFake = "fake"
+)
+
+// Estados de una intención de pago en la pasarela.
+const (
+	IntentPendienteCaptura = "requires_capture"
+	IntentCapturada        = "succeeded"
+	IntentRechazada        = "declined"
+	IntentReembolsada      = "refunded"
+)
+
+// Tipos de evento que la pasarela notifica por webhook.
+const (
+	EventoPagoExitoso     = "payment.succeeded"
+	EventoPagoRechazado   = "payment.declined"
+	EventoPagoReembolsado = "payment.refunded"
+)
+
+var (
+	// ErrIntentNoEncontrada indica que la pasarela no conoce la intención pedida.
+	ErrIntentNoEncontrada = errors.New("intención de pago no encontrada")
+	// ErrFirmaInvalida indica que el webhook no fue firmado por la pasarela.
+	ErrFirmaInvalida = errors.New("firma de webhook inválida")
+)
+
+// IntentRequest son los datos necesarios para iniciar un cobro.
+type IntentRequest struct {
+	Amount    float64
+	Currency  string
+	CardToken string
+	// Reference identifica el pago local (PaymentID) y vuelve en los webhooks.
+	Reference string
+}
+
+// Intent es una intención de pago tal como la reporta la pasarela.
+type Intent struct {
+	ID            string
+	Amount        float64
+	Currency      string
+	Status        string
+	Reference     string
+	DeclineReason string
+}
+
+// Refund es el resultado de un reembolso.
+type Refund struct {
+	ID       string
+	IntentID string
+	Amount   float64
+}
+
+// WebhookEvent es una notificación asíncrona verificada de la pasarela.
+type WebhookEvent struct {
+	Type      string `json:"type"`
+	IntentID  string `json:"intentID"`
+	Reference string `json:"reference"`
+}
+
+// PaymentGateway abstrae al proveedor de pagos para que los resolvers no
+// dependan de una implementación concreta.
+type PaymentGateway interface {
+	// CreateIntent registra un cobro. Si la tarjeta es rechazada la intención
+	// vuelve con estado IntentRechazada y sin error.
+	CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error)
+	// Capture cobra una intención previamente autorizada.
+	Capture(ctx context.Context, intentID string) (*Intent, error)
+	// Refund devuelve el monto indicado de una intención capturada. Es
+	// idempotente por intención: repetirlo devuelve el mismo reembolso.
+	Refund(ctx context.Context, intentID string, amount float64) (*Refund, error)
+	// VerifyWebhook valida la firma de un webhook y decodifica su contenido.
+	VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error)
+}
+
+// New crea la pasarela del proveedor indicado. secreto firma y verifica los
+// webhooks.
+func New(proveedor string, secreto string) (PaymentGateway, error) {
+	switch proveedor {
+	case ProveedorFake:
+		return NewFakeGateway(secreto), nil
+	}
+	return nil, fmt.Errorf("proveedor de pagos desconocido: %q", proveedor)
+}
@@ -0,0 +1,62 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+// tamañoBuffer es la cantidad de mensajes que un suscriptor lento puede
+// acumular antes de que se empiecen a descartar.
+const tamañoBuffer = 16
+
+// Broker distribuye mensajes en memoria a los suscriptores de cada tema.
+// Solo entrega a clientes conectados a este mismo proceso.
+type Broker[T any] struct {
+	mu   sync.RWMutex
+	subs map[string]map[chan T]struct{}
+}
+
+// NewBroker crea un broker vacío.
+func NewBroker[T any]() *Broker[T] {
+	return &Broker[T]{subs: make(map[string]map[chan T]struct{})}
+}
+
+// Subscribe registra un suscriptor al tema. El canal se cierra y se da de
+// baja cuando ctx termina, por ejemplo al desconectarse el websocket.
+func (b *Broker[T]) Subscribe(ctx context.Context, tema string) <-chan T {
+	ch := make(chan T, tamañoBuffer)
+
+	b.mu.Lock()
+	if b.subs[tema] == nil {
+		b.subs[tema] = make(map[chan T]struct{})
+	}
+	b.subs[tema][ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.subs[tema], ch)
+		if len(b.subs[tema]) == 0 {
+			delete(b.subs, tema)
+		}
+		close(ch)
+		b.mu.Unlock()
+	}()
+
+	return ch
+}
+
+// Publish envía el mensaje a todos los suscriptores del tema sin bloquear;
+// si el buffer de un suscriptor está lleno, ese mensaje se descarta para él.
+func (b *Broker[T]) Publish(tema string, msg T) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subs[tema] {
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+}
@@ -0,0 +1,622 @@
+package repository
+
+import (
+	"ProyectoIngeso/models"
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// FakeStore es un Store en memoria para desarrollo y pruebas. Respeta las
+// mismas reglas de unicidad que las tablas: username y email por usuario,
+// un curso por carrito, una inscripción y una reseña por curso de cada
+// usuario y un item por curso de cada pago.
+type FakeStore struct {
+	mu    sync.Mutex
+	datos *datosFake
+}
+
+// datosFake es el contenido del FakeStore, indexado por clave primaria.
+type datosFake struct {
+	usuarios       map[string]models.Usuario
+	carritos       map[string]models.Carrito
+	inscripciones  map[string]models.UsuarioCurso
+	pagos          map[string]models.Pago
+	resenas        map[string]models.Reseña
+	notificaciones map[string]models.Notificación
+}
+
+// NewFakeStore crea un FakeStore vacío.
+func NewFakeStore() *FakeStore {
+	return &FakeStore{datos: &datosFake{
+		usuarios:       map[string]models.Usuario{},
+		carritos:       map[string]models.Carrito{},
+		inscripciones:  map[string]models.UsuarioCurso{},
+		pagos:          map[string]models.Pago{},
+		resenas:        map[string]models.Reseña{},
+		notificaciones: map[string]models.Notificación{},
+	}}
+}
+
+// Repositorios devuelve repositorios que toman el candado en cada llamada.
+func (s *FakeStore) Repositorios() Repositorios {
+	return s.repositorios(&vistaFake{mu: &s.mu, datos: s.datos})
+}
+
+// EnTransaccion ejecuta fn sobre una copia de los datos con el candado
+// tomado durante toda la transacción; la copia reemplaza a los datos solo
+// si fn termina sin error.
+func (s *FakeStore) EnTransaccion(ctx context.Context, fn func(repos Repositorios) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copia := s.datos.clonar()
+	if err := fn(s.repositorios(&vistaFake{datos: copia})); err != nil {
+		return err
+	}
+	*s.datos = *copia
+	return nil
+}
+
+func (s *FakeStore) repositorios(v *vistaFake) Repositorios {
+	return Repositorios{
+		Usuarios:       &FakeUserRepository{v},
+		Carritos:       &FakeCartRepository{v},
+		Inscripciones:  &FakeEnrollmentRepository{v},
+		Pagos:          &FakePaymentRepository{v},
+		Resenas:        &FakeReviewRepository{v},
+		Notificaciones: &FakeNotificationRepository{v},
+	}
+}
+
+func (d *datosFake) clonar() *datosFake {
+	return &datosFake{
+		usuarios:       maps.Clone(d.usuarios),
+		carritos:       maps.Clone(d.carritos),
+		inscripciones:  maps.Clone(d.inscripciones),
+		pagos:          maps.Clone(d.pagos),
+		resenas:        maps.Clone(d.resenas),
+		notificaciones: maps.Clone(d.notificaciones),
+	}
+}
+
+// vistaFake es el acceso de los repositorios a los datos. Dentro de una
+// transacción mu es nil porque el candado ya lo tiene EnTransaccion.
+type vistaFake struct {
+	mu    *sync.Mutex
+	datos *datosFake
+}
+
+// bloquear toma el candado, si corresponde, y devuelve la función que lo suelta.
+func (v *vistaFake) bloquear() func() {
+	if v.mu == nil {
+		return func() {}
+	}
+	v.mu.Lock()
+	return v.mu.Unlock
+}
+
+// FakeUserRepository implementa UserRepository en memoria.
+type FakeUserRepository struct {
+	v *vistaFake
+}
+
+func (r *FakeUserRepository) buscar(coincide func(u models.Usuario) bool) (*models.Usuario, error) {
+	defer r.v.bloquear()()
+	for _, usuario := range r.v.datos.usuarios {
+		if coincide(usuario) {
+			return &usuario, nil
+		}
+	}
+	return nil, ErrNoEncontrado
+}
+
+func (r *FakeUserRepository) PorID(ctx context.Context, userID string) (*models.Usuario, error) {
+	return r.buscar(func(u models.Usuario) bool { return u.UserID == userID })
+}
+
+func (r *FakeUserRepository) PorEmail(ctx context.Context, email string) (*models.Usuario, error) {
+	return r.buscar(func(u models.Usuario) bool { return u.Email == email })
+}
+
+func (r *FakeUserRepository) PorUsername(ctx context.Context, username string) (*models.Usuario, error) {
+	return r.buscar(func(u models.Usuario) bool { return u.Username == username })
+}
+
+func (r *FakeUserRepository) PorIdentificador(ctx context.Context, identificador string) (*models.Usuario, error) {
+	return r.buscar(func(u models.Usuario) bool { return u.Email == identificador || u.Username == identificador })
+}
+
+func (r *FakeUserRepository) Todos(ctx context.Context) ([]models.Usuario, error) {
+	defer r.v.bloquear()()
+	usuarios := make([]models.Usuario, 0, len(r.v.datos.usuarios))
+	for _, usuario := range r.v.datos.usuarios {
+		usuarios = append(usuarios, usuario)
+	}
+	sort.Slice(usuarios, func(i, j int) bool { return usuarios[i].UserID < usuarios[j].UserID })
+	return usuarios, nil
+}
+
+func (r *FakeUserRepository) UsernameEnUso(ctx context.Context, username string, excluirID string) (bool, error) {
+	_, err := r.buscar(func(u models.Usuario) bool { return u.Username == username && u.UserID != excluirID })
+	return err == nil, nil
+}
+
+func (r *FakeUserRepository) EmailEnUso(ctx context.Context, email string, excluirID string) (bool, error) {
+	_, err := r.buscar(func(u models.Usuario) bool { return u.Email == email && u.UserID != excluirID })
+	return err == nil, nil
+}
+
+// chocaCon indica si otro usuario ya tiene el username o el email de usuario.
+func (r *FakeUserRepository) chocaCon(usuario models.Usuario) bool {
+	for _, otro := range r.v.datos.usuarios {
+		if otro.UserID != usuario.UserID && (otro.Username == usuario.Username || otro.Email == usuario.Email) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *FakeUserRepository) Crear(ctx context.Context, usuario *models.Usuario) error {
+	defer r.v.bloquear()()
+	if _, existe := r.v.datos.usuarios[usuario.UserID]; existe || r.chocaCon(*usuario) {
+		return ErrDuplicado
+	}
+	r.v.datos.usuarios[usuario.UserID] = *usuario
+	return nil
+}
+
+func (r *FakeUserRepository) Actualizar(ctx context.Context, usuario *models.Usuario, cambios CambiosUsuario) error {
+	defer r.v.bloquear()()
+	guardado, ok := r.v.datos.usuarios[usuario.UserID]
+	if !ok {
+		return ErrNoEncontrado
+	}
+	cambios.aplicar(&guardado)
+	if r.chocaCon(guardado) {
+		return ErrDuplicado
+	}
+	r.v.datos.usuarios[usuario.UserID] = guardado
+	cambios.aplicar(usuario)
+	return nil
+}
+
+// Eliminar borra el usuario junto con su carrito, sus inscripciones, sus
+// pagos, sus reseñas y sus notificaciones, como hacen las claves foráneas
+// con ON DELETE CASCADE.
+func (r *FakeUserRepository) Eliminar(ctx context.Context, userID string) error {
+	defer r.v.bloquear()()
+	if _, ok := r.v.datos.usuarios[userID]; !ok {
+		return ErrNoEncontrado
+	}
+	delete(r.v.datos.usuarios, userID)
+	maps.DeleteFunc(r.v.datos.carritos, func(_ string, c models.Carrito) bool { return c.UserID == userID })
+	maps.DeleteFunc(r.v.datos.inscripciones, func(_ string, i models.UsuarioCurso) bool { return i.UserID == userID })
+	maps.DeleteFunc(r.v.datos.pagos, func(_ string, p models.Pago) bool { return p.UserID == userID })
+	maps.DeleteFunc(r.v.datos.resenas, func(_ string, re models.Reseña) bool { return re.UserID == userID })
+	maps.DeleteFunc(r.v.datos.notificaciones, func(_ string, n models.Notificación) bool { return n.UserID == userID })
+	return nil
+}
+
+// FakeCartRepository implementa CartRepository en memoria.
+type FakeCartRepository struct {
+	v *vistaFake
+}
+
+// filtrar devuelve los items que cumplen coincide, ordenados por cartID.
+func (r *FakeCartRepository) filtrar(coincide func(c models.Carrito) bool) []models.Carrito {
+	var items []models.Carrito
+	for _, item := range r.v.datos.carritos {
+		if coincide(item) {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].CartID < items[j].CartID })
+	return items
+}
+
+func (r *FakeCartRepository) PorID(ctx context.Context, cartID string) (*models.Carrito, error) {
+	defer r.v.bloquear()()
+	item, ok := r.v.datos.carritos[cartID]
+	if !ok {
+		return nil, ErrNoEncontrado
+	}
+	return &item, nil
+}
+
+func (r *FakeCartRepository) Items(ctx context.Context, userID string) ([]models.Carrito, error) {
+	defer r.v.bloquear()()
+	return r.filtrar(func(c models.Carrito) bool { return c.UserID == userID }), nil
+}
+
+func (r *FakeCartRepository) Pagina(ctx context.Context, userID string, despuesDe string, limite int) ([]models.Carrito, error) {
+	defer r.v.bloquear()()
+	items := r.filtrar(func(c models.Carrito) bool {
+		return c.UserID == userID && c.CartID > despuesDe
+	})
+	if len(items) > limite {
+		items = items[:limite]
+	}
+	return items, nil
+}
+
+func (r *FakeCartRepository) Contar(ctx context.Context, userID string) (int, error) {
+	defer r.v.bloquear()()
+	return len(r.filtrar(func(c models.Carrito) bool { return c.UserID == userID })), nil
+}
+
+func (r *FakeCartRepository) Contiene(ctx context.Context, userID string, courseID string) (bool, error) {
+	defer r.v.bloquear()()
+	return len(r.filtrar(func(c models.Carrito) bool { return c.UserID == userID && c.CourseID == courseID })) > 0, nil
+}
+
+func (r *FakeCartRepository) UsuariosConCurso(ctx context.Context, courseID string) ([]string, error) {
+	defer r.v.bloquear()()
+	var userIDs []string
+	for _, item := range r.filtrar(func(c models.Carrito) bool { return c.CourseID == courseID }) {
+		if !slices.Contains(userIDs, item.UserID) {
+			userIDs = append(userIDs, item.UserID)
+		}
+	}
+	return userIDs, nil
+}
+
+func (r *FakeCartRepository) Agregar(ctx context.Context, item *models.Carrito) error {
+	defer r.v.bloquear()()
+	if _, existe := r.v.datos.carritos[item.CartID]; existe {
+		return ErrDuplicado
+	}
+	for _, otro := range r.v.datos.carritos {
+		if otro.UserID == item.UserID && otro.CourseID == item.CourseID {
+			return ErrDuplicado
+		}
+	}
+	r.v.datos.carritos[item.CartID] = *item
+	return nil
+}
+
+func (r *FakeCartRepository) Eliminar(ctx context.Context, cartID string) error {
+	defer r.v.bloquear()()
+	delete(r.v.datos.carritos, cartID)
+	return nil
+}
+
+func (r *FakeCartRepository) Quitar(ctx context.Context, userID string, courseIDs ...string) error {
+	defer r.v.bloquear()()
+	maps.DeleteFunc(r.v.datos.carritos, func(_ string, c models.Carrito) bool {
+		return c.UserID == userID && slices.Contains(courseIDs, c.CourseID)
+	})
+	return nil
+}
+
+func (r *FakeCartRepository) QuitarCursoDeTodos(ctx context.Context, courseID string) error {
+	defer r.v.bloquear()()
+	maps.DeleteFunc(r.v.datos.carritos, func(_ string, c models.Carrito) bool { return c.CourseID == courseID })
+	return nil
+}
+
+func (r *FakeCartRepository) Vaciar(ctx context.Context, userID string) error {
+	defer r.v.bloquear()()
+	maps.DeleteFunc(r.v.datos.carritos, func(_ string, c models.Carrito) bool { return c.UserID == userID })
+	return nil
+}
+
+// FakeEnrollmentRepository implementa EnrollmentRepository en memoria.
+type FakeEnrollmentRepository struct {
+	v *vistaFake
+}
+
+func (r *FakeEnrollmentRepository) EstaInscrito(ctx context.Context, userID string, courseID string) (bool, error) {
+	defer r.v.bloquear()()
+	for _, inscripcion := range r.v.datos.inscripciones {
+		if inscripcion.UserID == userID && inscripcion.CourseID == courseID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *FakeEnrollmentRepository) DeUsuario(ctx context.Context, userID string) ([]models.UsuarioCurso, error) {
+	defer r.v.bloquear()()
+	var inscripciones []models.UsuarioCurso
+	for _, inscripcion := range r.v.datos.inscripciones {
+		if inscripcion.UserID == userID {
+			inscripciones = append(inscripciones, inscripcion)
+		}
+	}
+	sort.Slice(inscripciones, func(i, j int) bool { return inscripciones[i].EnrolledAt < inscripciones[j].EnrolledAt })
+	return inscripciones, nil
+}
+
+func (r *FakeEnrollmentRepository) Crear(ctx context.Context, inscripcion *models.UsuarioCurso) error {
+	defer r.v.bloquear()()
+	if _, existe := r.v.datos.inscripciones[inscripcion.ID]; existe {
+		return ErrDuplicado
+	}
+	for _, otra := range r.v.datos.inscripciones {
+		if otra.UserID == inscripcion.UserID && otra.CourseID == inscripcion.CourseID {
+			return ErrDuplicado
+		}
+	}
+	r.v.datos.inscripciones[inscripcion.ID] = *inscripcion
+	return nil
+}
+
+func (r *FakeEnrollmentRepository) RetirarPorPago(ctx context.Context, userID string, paymentID string) error {
+	defer r.v.bloquear()()
+	maps.DeleteFunc(r.v.datos.inscripciones, func(_ string, i models.UsuarioCurso) bool {
+		return i.UserID == userID && i.PaymentID != nil && *i.PaymentID == paymentID
+	})
+	return nil
+}
+
+// FakePaymentRepository implementa PaymentRepository en memoria. Los items
+// se copian al guardar y al devolver, para que nadie modifique los guardados.
+type FakePaymentRepository struct {
+	v *vistaFake
+}
+
+func (r *FakePaymentRepository) buscar(coincide func(p models.Pago) bool) (*models.Pago, error) {
+	defer r.v.bloquear()()
+	for _, pago := range r.v.datos.pagos {
+		if coincide(pago) {
+			pago.Items = slices.Clone(pago.Items)
+			return &pago, nil
+		}
+	}
+	return nil, ErrNoEncontrado
+}
+
+func (r *FakePaymentRepository) PorID(ctx context.Context, paymentID string) (*models.Pago, error) {
+	return r.buscar(func(p models.Pago) bool { return p.PaymentID == paymentID })
+}
+
+func (r *FakePaymentRepository) PorReferencia(ctx context.Context, paymentID string, gatewayRef string) (*models.Pago, error) {
+	return r.buscar(func(p models.Pago) bool { return p.PaymentID == paymentID && p.GatewayRef == gatewayRef })
+}
+
+func (r *FakePaymentRepository) DeUsuario(ctx context.Context, userID string) ([]models.Pago, error) {
+	defer r.v.bloquear()()
+	var pagos []models.Pago
+	for _, pago := range r.v.datos.pagos {
+		if pago.UserID == userID {
+			pago.Items = slices.Clone(pago.Items)
+			pagos = append(pagos, pago)
+		}
+	}
+	sort.Slice(pagos, func(i, j int) bool { return pagos[i].PaymentDate > pagos[j].PaymentDate })
+	return pagos, nil
+}
+
+func (r *FakePaymentRepository) ContarPendientes(ctx context.Context, userID string) (int, error) {
+	defer r.v.bloquear()()
+	pendientes := 0
+	for _, pago := range r.v.datos.pagos {
+		if pago.UserID == userID && pago.Status == models.EstadoPagoPendiente {
+			pendientes++
+		}
+	}
+	return pendientes, nil
+}
+
+func (r *FakePaymentRepository) Crear(ctx context.Context, pago *models.Pago) error {
+	defer r.v.bloquear()()
+	if _, existe := r.v.datos.pagos[pago.PaymentID]; existe {
+		return ErrDuplicado
+	}
+	cursos := map[string]bool{}
+	for _, item := range pago.Items {
+		if cursos[item.CourseID] || r.existeItem(item.ItemID) {
+			return ErrDuplicado
+		}
+		cursos[item.CourseID] = true
+	}
+
+	guardado := *pago
+	guardado.Items = slices.Clone(pago.Items)
+	r.v.datos.pagos[pago.PaymentID] = guardado
+	return nil
+}
+
+func (r *FakePaymentRepository) existeItem(itemID string) bool {
+	for _, pago := range r.v.datos.pagos {
+		if slices.ContainsFunc(pago.Items, func(item models.PagoItem) bool { return item.ItemID == itemID }) {
+			return true
+		}
+	}
+	return false
+}
+
+// actualizar aplica cambio al pago guardado.
+func (r *FakePaymentRepository) actualizar(paymentID string, cambio func(p *models.Pago)) error {
+	defer r.v.bloquear()()
+	pago, ok := r.v.datos.pagos[paymentID]
+	if !ok {
+		return ErrNoEncontrado
+	}
+	cambio(&pago)
+	r.v.datos.pagos[paymentID] = pago
+	return nil
+}
+
+func (r *FakePaymentRepository) CambiarEstado(ctx context.Context, paymentID string, estado string) error {
+	return r.actualizar(paymentID, func(p *models.Pago) { p.Status = estado })
+}
+
+func (r *FakePaymentRepository) AsignarReferencia(ctx context.Context, paymentID string, gatewayRef string) error {
+	return r.actualizar(paymentID, func(p *models.Pago) { p.GatewayRef = gatewayRef })
+}
+
+// FakeReviewRepository implementa ReviewRepository en memoria.
+type FakeReviewRepository struct {
+	v *vistaFake
+}
+
+func (r *FakeReviewRepository) PorID(ctx context.Context, reviewID string) (*models.Reseña, error) {
+	defer r.v.bloquear()()
+	resena, ok := r.v.datos.resenas[reviewID]
+	if !ok {
+		return nil, ErrNoEncontrado
+	}
+	return &resena, nil
+}
+
+// deCurso devuelve las reseñas del curso, de la más reciente a la más antigua.
+func (r *FakeReviewRepository) deCurso(courseID string) []models.Reseña {
+	var resenas []models.Reseña
+	for _, resena := range r.v.datos.resenas {
+		if resena.CourseID == courseID {
+			resenas = append(resenas, resena)
+		}
+	}
+	sort.Slice(resenas, func(i, j int) bool { return resenas[i].CreatedAt > resenas[j].CreatedAt })
+	return resenas
+}
+
+func (r *FakeReviewRepository) DeCurso(ctx context.Context, courseID string) ([]models.Reseña, error) {
+	defer r.v.bloquear()()
+	return r.deCurso(courseID), nil
+}
+
+func (r *FakeReviewRepository) Existe(ctx context.Context, userID string, courseID string) (bool, error) {
+	defer r.v.bloquear()()
+	return slices.ContainsFunc(r.deCurso(courseID), func(re models.Reseña) bool { return re.UserID == userID }), nil
+}
+
+func (r *FakeReviewRepository) ContarPorCalificacion(ctx context.Context, courseID string) (map[int]int, error) {
+	defer r.v.bloquear()()
+	conteo := map[int]int{}
+	for _, resena := range r.deCurso(courseID) {
+		conteo[resena.Rating]++
+	}
+	return conteo, nil
+}
+
+func (r *FakeReviewRepository) Crear(ctx context.Context, resena *models.Reseña) error {
+	defer r.v.bloquear()()
+	if _, existe := r.v.datos.resenas[resena.ReviewID]; existe {
+		return ErrDuplicado
+	}
+	if slices.ContainsFunc(r.deCurso(resena.CourseID), func(re models.Reseña) bool { return re.UserID == resena.UserID }) {
+		return ErrDuplicado
+	}
+	r.v.datos.resenas[resena.ReviewID] = *resena
+	return nil
+}
+
+// actualizar aplica cambio a la reseña guardada.
+func (r *FakeReviewRepository) actualizar(reviewID string, cambio func(re *models.Reseña)) error {
+	defer r.v.bloquear()()
+	resena, ok := r.v.datos.resenas[reviewID]
+	if !ok {
+		return ErrNoEncontrado
+	}
+	cambio(&resena)
+	r.v.datos.resenas[reviewID] = resena
+	return nil
+}
+
+func (r *FakeReviewRepository) Editar(ctx context.Context, resena *models.Reseña) error {
+	return r.actualizar(resena.ReviewID, func(re *models.Reseña) {
+		re.Rating = resena.Rating
+		re.Comments = resena.Comments
+		re.UpdatedAt = resena.UpdatedAt
+	})
+}
+
+func (r *FakeReviewRepository) Responder(ctx context.Context, resena *models.Reseña) error {
+	return r.actualizar(resena.ReviewID, func(re *models.Reseña) {
+		re.Reply = resena.Reply
+		re.RepliedAt = resena.RepliedAt
+	})
+}
+
+func (r *FakeReviewRepository) Eliminar(ctx context.Context, reviewID string) error {
+	defer r.v.bloquear()()
+	delete(r.v.datos.resenas, reviewID)
+	return nil
+}
+
+// FakeNotificationRepository implementa NotificationRepository en memoria.
+type FakeNotificationRepository struct {
+	v *vistaFake
+}
+
+func (r *FakeNotificationRepository) PorID(ctx context.Context, notificationID string) (*models.Notificación, error) {
+	defer r.v.bloquear()()
+	notificacion, ok := r.v.datos.notificaciones[notificationID]
+	if !ok {
+		return nil, ErrNoEncontrado
+	}
+	return &notificacion, nil
+}
+
+func (r *FakeNotificationRepository) Pagina(ctx context.Context, userID string, estado string, antesDeFecha string, antesDeID string, limite int) ([]models.Notificación, error) {
+	defer r.v.bloquear()()
+	var notificaciones []models.Notificación
+	for _, n := range r.v.datos.notificaciones {
+		if n.UserID != userID || (estado != "" && n.Status != estado) {
+			continue
+		}
+		if antesDeID != "" && (n.CreatedAt > antesDeFecha || (n.CreatedAt == antesDeFecha && n.NotificationID >= antesDeID)) {
+			continue
+		}
+		notificaciones = append(notificaciones, n)
+	}
+	sort.Slice(notificaciones, func(i, j int) bool {
+		if notificaciones[i].CreatedAt != notificaciones[j].CreatedAt {
+			return notificaciones[i].CreatedAt > notificaciones[j].CreatedAt
+		}
+		return notificaciones[i].NotificationID > notificaciones[j].NotificationID
+	})
+	if len(notificaciones) > limite {
+		notificaciones = notificaciones[:limite]
+	}
+	return notificaciones, nil
+}
+
+func (r *FakeNotificationRepository) ContarNoLeidas(ctx context.Context, userID string) (int, error) {
+	defer r.v.bloquear()()
+	total := 0
+	for _, n := range r.v.datos.notificaciones {
+		if n.UserID == userID && n.Status == models.NotificacionNoLeida {
+			total++
+		}
+	}
+	return total, nil
+}
+
+func (r *FakeNotificationRepository) Crear(ctx context.Context, notificacion *models.Notificación) error {
+	defer r.v.bloquear()()
+	if _, existe := r.v.datos.notificaciones[notificacion.NotificationID]; existe {
+		return ErrDuplicado
+	}
+	r.v.datos.notificaciones[notificacion.NotificationID] = *notificacion
+	return nil
+}
+
+func (r *FakeNotificationRepository) MarcarLeida(ctx context.Context, notificationID string) error {
+	defer r.v.bloquear()()
+	notificacion, ok := r.v.datos.notificaciones[notificationID]
+	if !ok {
+		return ErrNoEncontrado
+	}
+	notificacion.Status = models.NotificacionLeida
+	r.v.datos.notificaciones[notificationID] = notificacion
+	return nil
+}
+
+func (r *FakeNotificationRepository) MarcarTodasLeidas(ctx context.Context, userID string) (int, error) {
+	defer r.v.bloquear()()
+	cambiadas := 0
+	for id, n := range r.v.datos.notificaciones {
+		if n.UserID == userID && n.Status == models.NotificacionNoLeida {
+			n.Status = models.NotificacionLeida
+			r.v.datos.notificaciones[id] = n
+			cambiadas++
+		}
+	}
+	return cambiadas, nil
+}
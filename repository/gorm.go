@@ -0,0 +1,523 @@
+package repository
+
+import (
+	"ProyectoIngeso/database"
+	"ProyectoIngeso/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// GormStore implementa Store sobre la base de datos de la aplicación.
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore crea un Store que usa db.
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+// Repositorios devuelve repositorios que usan la conexión sin transacción.
+func (s *GormStore) Repositorios() Repositorios {
+	return NewGormRepositorios(s.db)
+}
+
+// EnTransaccion ejecuta fn en una transacción que se reintenta ante
+// conflictos de concurrencia.
+func (s *GormStore) EnTransaccion(ctx context.Context, fn func(repos Repositorios) error) error {
+	return database.EnTransaccion(ctx, s.db, func(tx *gorm.DB) error {
+		return fn(NewGormRepositorios(tx))
+	})
+}
+
+// NewGormRepositorios arma los repositorios sobre db, que puede ser una
+// transacción abierta por quien los usa.
+func NewGormRepositorios(db *gorm.DB) Repositorios {
+	return Repositorios{
+		Usuarios:       &GormUserRepository{db: db},
+		Carritos:       &GormCartRepository{db: db},
+		Inscripciones:  &GormEnrollmentRepository{db: db},
+		Pagos:          &GormPaymentRepository{db: db},
+		Resenas:        &GormReviewRepository{db: db},
+		Notificaciones: &GormNotificationRepository{db: db},
+	}
+}
+
+// errorGorm traduce los errores de GORM a los del paquete. accion describe
+// la operación para el mensaje de los demás errores.
+func errorGorm(err error, accion string) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNoEncontrado
+	case database.EsDuplicado(err):
+		return fmt.Errorf("%w: %v", ErrDuplicado, err)
+	}
+	return fmt.Errorf("error al %s: %w", accion, err)
+}
+
+// GormUserRepository implementa UserRepository sobre la tabla usuarios.
+type GormUserRepository struct {
+	db *gorm.DB
+}
+
+func (r *GormUserRepository) buscar(ctx context.Context, condicion string, valores ...interface{}) (*models.Usuario, error) {
+	var usuario models.Usuario
+	if err := r.db.WithContext(ctx).Where(condicion, valores...).First(&usuario).Error; err != nil {
+		return nil, errorGorm(err, "buscar el usuario")
+	}
+	return &usuario, nil
+}
+
+func (r *GormUserRepository) PorID(ctx context.Context, userID string) (*models.Usuario, error) {
+	return r.buscar(ctx, "user_id = ?", userID)
+}
+
+func (r *GormUserRepository) PorEmail(ctx context.Context, email string) (*models.Usuario, error) {
+	return r.buscar(ctx, "email = ?", email)
+}
+
+func (r *GormUserRepository) PorUsername(ctx context.Context, username string) (*models.Usuario, error) {
+	return r.buscar(ctx, "username = ?", username)
+}
+
+func (r *GormUserRepository) PorIdentificador(ctx context.Context, identificador string) (*models.Usuario, error) {
+	return r.buscar(ctx, "email = ? OR username = ?", identificador, identificador)
+}
+
+func (r *GormUserRepository) Todos(ctx context.Context) ([]models.Usuario, error) {
+	var usuarios []models.Usuario
+	if err := r.db.WithContext(ctx).Find(&usuarios).Error; err != nil {
+		return nil, errorGorm(err, "obtener los usuarios")
+	}
+	return usuarios, nil
+}
+
+// enUso cuenta los usuarios distintos de excluirID con valor en columna.
+func (r *GormUserRepository) enUso(ctx context.Context, columna string, valor string, excluirID string) (bool, error) {
+	var usados int64
+	consulta := r.db.WithContext(ctx).Model(&models.Usuario{}).Where(columna+" = ?", valor)
+	if excluirID != "" {
+		consulta = consulta.Where("user_id <> ?", excluirID)
+	}
+	if err := consulta.Count(&usados).Error; err != nil {
+		return false, errorGorm(err, "verificar el "+columna)
+	}
+	return usados > 0, nil
+}
+
+func (r *GormUserRepository) UsernameEnUso(ctx context.Context, username string, excluirID string) (bool, error) {
+	return r.enUso(ctx, "username", username, excluirID)
+}
+
+func (r *GormUserRepository) EmailEnUso(ctx context.Context, email string, excluirID string) (bool, error) {
+	return r.enUso(ctx, "email", email, excluirID)
+}
+
+func (r *GormUserRepository) Crear(ctx context.Context, usuario *models.Usuario) error {
+	return errorGorm(r.db.WithContext(ctx).Create(usuario).Error, "crear el usuario")
+}
+
+func (r *GormUserRepository) Actualizar(ctx context.Context, usuario *models.Usuario, cambios CambiosUsuario) error {
+	columnas := map[string]interface{}{}
+	for columna, valor := range map[string]*string{
+		"username":       cambios.Username,
+		"email":          cambios.Email,
+		"password":       cambios.Password,
+		"name_last_name": cambios.NameLastName,
+		"role":           cambios.Role,
+	} {
+		if valor != nil {
+			columnas[columna] = *valor
+		}
+	}
+	if len(columnas) == 0 {
+		return nil
+	}
+
+	resultado := r.db.WithContext(ctx).Model(&models.Usuario{}).Where("user_id = ?", usuario.UserID).Updates(columnas)
+	if err := errorGorm(resultado.Error, "actualizar el usuario"); err != nil {
+		return err
+	}
+	if resultado.RowsAffected == 0 {
+		return ErrNoEncontrado
+	}
+	cambios.aplicar(usuario)
+	return nil
+}
+
+func (r *GormUserRepository) Eliminar(ctx context.Context, userID string) error {
+	resultado := r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.Usuario{})
+	if err := errorGorm(resultado.Error, "eliminar el usuario"); err != nil {
+		return err
+	}
+	if resultado.RowsAffected == 0 {
+		return ErrNoEncontrado
+	}
+	return nil
+}
+
+// aplicar copia los cambios sobre usuario.
+func (c CambiosUsuario) aplicar(usuario *models.Usuario) {
+	if c.Username != nil {
+		usuario.Username = *c.Username
+	}
+	if c.Email != nil {
+		usuario.Email = *c.Email
+	}
+	if c.Password != nil {
+		usuario.Password = *c.Password
+	}
+	if c.NameLastName != nil {
+		usuario.NameLastName = *c.NameLastName
+	}
+	if c.Role != nil {
+		usuario.Role = *c.Role
+	}
+}
+
+// GormCartRepository implementa CartRepository sobre la tabla carritos.
+type GormCartRepository struct {
+	db *gorm.DB
+}
+
+func (r *GormCartRepository) PorID(ctx context.Context, cartID string) (*models.Carrito, error) {
+	var item models.Carrito
+	if err := r.db.WithContext(ctx).First(&item, "cart_id = ?", cartID).Error; err != nil {
+		return nil, errorGorm(err, "buscar el carrito")
+	}
+	return &item, nil
+}
+
+func (r *GormCartRepository) Items(ctx context.Context, userID string) ([]models.Carrito, error) {
+	var items []models.Carrito
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("cart_id").Find(&items).Error; err != nil {
+		return nil, errorGorm(err, "obtener el carrito")
+	}
+	return items, nil
+}
+
+func (r *GormCartRepository) Pagina(ctx context.Context, userID string, despuesDe string, limite int) ([]models.Carrito, error) {
+	consulta := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if despuesDe != "" {
+		consulta = consulta.Where("cart_id > ?", despuesDe)
+	}
+
+	var items []models.Carrito
+	if err := consulta.Order("cart_id").Limit(limite).Find(&items).Error; err != nil {
+		return nil, errorGorm(err, "obtener el carrito")
+	}
+	return items, nil
+}
+
+func (r *GormCartRepository) Contar(ctx context.Context, userID string) (int, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&models.Carrito{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		return 0, errorGorm(err, "obtener el carrito")
+	}
+	return int(total), nil
+}
+
+func (r *GormCartRepository) Contiene(ctx context.Context, userID string, courseID string) (bool, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&models.Carrito{}).
+		Where("user_id = ? AND course_id = ?", userID, courseID).
+		Count(&total).Error; err != nil {
+		return false, errorGorm(err, "verificar el carrito")
+	}
+	return total > 0, nil
+}
+
+func (r *GormCartRepository) UsuariosConCurso(ctx context.Context, courseID string) ([]string, error) {
+	var userIDs []string
+	if err := r.db.WithContext(ctx).Model(&models.Carrito{}).
+		Where("course_id = ?", courseID).
+		Distinct().Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, errorGorm(err, "obtener los carritos con el curso")
+	}
+	return userIDs, nil
+}
+
+func (r *GormCartRepository) Agregar(ctx context.Context, item *models.Carrito) error {
+	return errorGorm(r.db.WithContext(ctx).Create(item).Error, "agregar al carrito")
+}
+
+func (r *GormCartRepository) Eliminar(ctx context.Context, cartID string) error {
+	return errorGorm(r.db.WithContext(ctx).Where("cart_id = ?", cartID).Delete(&models.Carrito{}).Error, "eliminar el carrito")
+}
+
+func (r *GormCartRepository) Quitar(ctx context.Context, userID string, courseIDs ...string) error {
+	if len(courseIDs) == 0 {
+		return nil
+	}
+	return errorGorm(r.db.WithContext(ctx).
+		Where("user_id = ? AND course_id IN ?", userID, courseIDs).
+		Delete(&models.Carrito{}).Error, "quitar del carrito")
+}
+
+func (r *GormCartRepository) QuitarCursoDeTodos(ctx context.Context, courseID string) error {
+	return errorGorm(r.db.WithContext(ctx).Where("course_id = ?", courseID).Delete(&models.Carrito{}).Error, "eliminar los carritos con el curso")
+}
+
+func (r *GormCartRepository) Vaciar(ctx context.Context, userID string) error {
+	return errorGorm(r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.Carrito{}).Error, "vaciar el carrito")
+}
+
+// GormEnrollmentRepository implementa EnrollmentRepository sobre la tabla
+// usuario_cursos.
+type GormEnrollmentRepository struct {
+	db *gorm.DB
+}
+
+func (r *GormEnrollmentRepository) EstaInscrito(ctx context.Context, userID string, courseID string) (bool, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&models.UsuarioCurso{}).
+		Where("user_id = ? AND course_id = ?", userID, courseID).
+		Count(&total).Error; err != nil {
+		return false, errorGorm(err, "verificar la inscripción")
+	}
+	return total > 0, nil
+}
+
+func (r *GormEnrollmentRepository) DeUsuario(ctx context.Context, userID string) ([]models.UsuarioCurso, error) {
+	var inscripciones []models.UsuarioCurso
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).
+		Order("enrolled_at").Find(&inscripciones).Error; err != nil {
+		return nil, errorGorm(err, "obtener las inscripciones")
+	}
+	return inscripciones, nil
+}
+
+func (r *GormEnrollmentRepository) Crear(ctx context.Context, inscripcion *models.UsuarioCurso) error {
+	return errorGorm(r.db.WithContext(ctx).Create(inscripcion).Error, "inscribir el curso "+inscripcion.CourseID)
+}
+
+func (r *GormEnrollmentRepository) RetirarPorPago(ctx context.Context, userID string, paymentID string) error {
+	return errorGorm(r.db.WithContext(ctx).
+		Where("user_id = ? AND payment_id = ?", userID, paymentID).
+		Delete(&models.UsuarioCurso{}).Error, "retirar las inscripciones del pago")
+}
+
+// GormPaymentRepository implementa PaymentRepository sobre las tablas pagos
+// y pago_items.
+type GormPaymentRepository struct {
+	db *gorm.DB
+}
+
+func (r *GormPaymentRepository) buscar(ctx context.Context, condicion string, valores ...interface{}) (*models.Pago, error) {
+	var pago models.Pago
+	if err := r.db.WithContext(ctx).Preload("Items").Where(condicion, valores...).First(&pago).Error; err != nil {
+		return nil, errorGorm(err, "buscar el pago")
+	}
+	return &pago, nil
+}
+
+func (r *GormPaymentRepository) PorID(ctx context.Context, paymentID string) (*models.Pago, error) {
+	return r.buscar(ctx, "payment_id = ?", paymentID)
+}
+
+func (r *GormPaymentRepository) PorReferencia(ctx context.Context, paymentID string, gatewayRef string) (*models.Pago, error) {
+	return r.buscar(ctx, "payment_id = ? AND gateway_ref = ?", paymentID, gatewayRef)
+}
+
+func (r *GormPaymentRepository) DeUsuario(ctx context.Context, userID string) ([]models.Pago, error) {
+	var pagos []models.Pago
+	if err := r.db.WithContext(ctx).Preload("Items").Where("user_id = ?", userID).
+		Order("payment_date DESC").Find(&pagos).Error; err != nil {
+		return nil, errorGorm(err, "obtener los pagos")
+	}
+	return pagos, nil
+}
+
+func (r *GormPaymentRepository) ContarPendientes(ctx context.Context, userID string) (int, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&models.Pago{}).
+		Where("user_id = ? AND status = ?", userID, models.EstadoPagoPendiente).
+		Count(&total).Error; err != nil {
+		return 0, errorGorm(err, "contar los pagos pendientes")
+	}
+	return int(total), nil
+}
+
+func (r *GormPaymentRepository) Crear(ctx context.Context, pago *models.Pago) error {
+	return errorGorm(r.db.WithContext(ctx).Omit("User").Create(pago).Error, "crear el pago")
+}
+
+// actualizar escribe columna en el pago y falla si el pago no existe.
+func (r *GormPaymentRepository) actualizar(ctx context.Context, paymentID string, columna string, valor string) error {
+	resultado := r.db.WithContext(ctx).Model(&models.Pago{}).Where("payment_id = ?", paymentID).Update(columna, valor)
+	if err := errorGorm(resultado.Error, "actualizar el pago"); err != nil {
+		return err
+	}
+	if resultado.RowsAffected == 0 {
+		return ErrNoEncontrado
+	}
+	return nil
+}
+
+func (r *GormPaymentRepository) CambiarEstado(ctx context.Context, paymentID string, estado string) error {
+	return r.actualizar(ctx, paymentID, "status", estado)
+}
+
+func (r *GormPaymentRepository) AsignarReferencia(ctx context.Context, paymentID string, gatewayRef string) error {
+	return r.actualizar(ctx, paymentID, "gateway_ref", gatewayRef)
+}
+
+// GormReviewRepository implementa ReviewRepository sobre la tabla reseñas.
+type GormReviewRepository struct {
+	db *gorm.DB
+}
+
+func (r *GormReviewRepository) PorID(ctx context.Context, reviewID string) (*models.Reseña, error) {
+	var resena models.Reseña
+	if err := r.db.WithContext(ctx).First(&resena, "review_id = ?", reviewID).Error; err != nil {
+		return nil, errorGorm(err, "buscar la reseña")
+	}
+	return &resena, nil
+}
+
+func (r *GormReviewRepository) DeCurso(ctx context.Context, courseID string) ([]models.Reseña, error) {
+	var resenas []models.Reseña
+	if err := r.db.WithContext(ctx).Where("course_id = ?", courseID).
+		Order("created_at DESC").Find(&resenas).Error; err != nil {
+		return nil, errorGorm(err, "obtener las reseñas")
+	}
+	return resenas, nil
+}
+
+func (r *GormReviewRepository) Existe(ctx context.Context, userID string, courseID string) (bool, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&models.Reseña{}).
+		Where("user_id = ? AND course_id = ?", userID, courseID).
+		Count(&total).Error; err != nil {
+		return false, errorGorm(err, "verificar las reseñas previas")
+	}
+	return total > 0, nil
+}
+
+func (r *GormReviewRepository) ContarPorCalificacion(ctx context.Context, courseID string) (map[int]int, error) {
+	var filas []struct {
+		Rating int
+		Total  int
+	}
+	if err := r.db.WithContext(ctx).Model(&models.Reseña{}).
+		Select("rating, COUNT(*) AS total").
+		Where("course_id = ?", courseID).
+		Group("rating").
+		Scan(&filas).Error; err != nil {
+		return nil, errorGorm(err, "contar las calificaciones")
+	}
+
+	conteo := make(map[int]int, len(filas))
+	for _, fila := range filas {
+		conteo[fila.Rating] = fila.Total
+	}
+	return conteo, nil
+}
+
+func (r *GormReviewRepository) Crear(ctx context.Context, resena *models.Reseña) error {
+	return errorGorm(r.db.WithContext(ctx).Omit("User").Create(resena).Error, "crear la reseña")
+}
+
+// actualizar escribe columnas en la reseña y falla si la reseña no existe.
+func (r *GormReviewRepository) actualizar(ctx context.Context, reviewID string, columnas map[string]interface{}) error {
+	resultado := r.db.WithContext(ctx).Model(&models.Reseña{}).Where("review_id = ?", reviewID).Updates(columnas)
+	if err := errorGorm(resultado.Error, "actualizar la reseña"); err != nil {
+		return err
+	}
+	if resultado.RowsAffected == 0 {
+		return ErrNoEncontrado
+	}
+	return nil
+}
+
+func (r *GormReviewRepository) Editar(ctx context.Context, resena *models.Reseña) error {
+	return r.actualizar(ctx, resena.ReviewID, map[string]interface{}{
+		"rating":     resena.Rating,
+		"comments":   resena.Comments,
+		"updated_at": resena.UpdatedAt,
+	})
+}
+
+func (r *GormReviewRepository) Responder(ctx context.Context, resena *models.Reseña) error {
+	return r.actualizar(ctx, resena.ReviewID, map[string]interface{}{
+		"reply":      resena.Reply,
+		"replied_at": resena.RepliedAt,
+	})
+}
+
+func (r *GormReviewRepository) Eliminar(ctx context.Context, reviewID string) error {
+	return errorGorm(r.db.WithContext(ctx).Where("review_id = ?", reviewID).Delete(&models.Reseña{}).Error, "eliminar la reseña")
+}
+
+// GormNotificationRepository implementa NotificationRepository sobre la
+// tabla notificacións.
+type GormNotificationRepository struct {
+	db *gorm.DB
+}
+
+func (r *GormNotificationRepository) PorID(ctx context.Context, notificationID string) (*models.Notificación, error) {
+	var notificacion models.Notificación
+	if err := r.db.WithContext(ctx).First(&notificacion, "notification_id = ?", notificationID).Error; err != nil {
+		return nil, errorGorm(err, "buscar la notificación")
+	}
+	return &notificacion, nil
+}
+
+func (r *GormNotificationRepository) Pagina(ctx context.Context, userID string, estado string, antesDeFecha string, antesDeID string, limite int) ([]models.Notificación, error) {
+	consulta := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if estado != "" {
+		consulta = consulta.Where("status = ?", estado)
+	}
+	if antesDeID != "" {
+		consulta = consulta.Where("created_at < ? OR (created_at = ? AND notification_id < ?)", antesDeFecha, antesDeFecha, antesDeID)
+	}
+
+	var notificaciones []models.Notificación
+	if err := consulta.Order("created_at DESC, notification_id DESC").
+		Limit(limite).Find(&notificaciones).Error; err != nil {
+		return nil, errorGorm(err, "obtener las notificaciones")
+	}
+	return notificaciones, nil
+}
+
+func (r *GormNotificationRepository) ContarNoLeidas(ctx context.Context, userID string) (int, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&models.Notificación{}).
+		Where("user_id = ? AND status = ?", userID, models.NotificacionNoLeida).
+		Count(&total).Error; err != nil {
+		return 0, errorGorm(err, "contar las notificaciones")
+	}
+	return int(total), nil
+}
+
+func (r *GormNotificationRepository) Crear(ctx context.Context, notificacion *models.Notificación) error {
+	return errorGorm(r.db.WithContext(ctx).Omit("User").Create(notificacion).Error, "crear la notificación")
+}
+
+func (r *GormNotificationRepository) MarcarLeida(ctx context.Context, notificationID string) error {
+	resultado := r.db.WithContext(ctx).Model(&models.Notificación{}).
+		Where("notification_id = ?", notificationID).
+		Update("status", models.NotificacionLeida)
+	if err := errorGorm(resultado.Error, "actualizar la notificación"); err != nil {
+		return err
+	}
+	if resultado.RowsAffected == 0 {
+		return ErrNoEncontrado
+	}
+	return nil
+}
+
+func (r *GormNotificationRepository) MarcarTodasLeidas(ctx context.Context, userID string) (int, error) {
+	resultado := r.db.WithContext(ctx).Model(&models.Notificación{}).
+		Where("user_id = ? AND status = ?", userID, models.NotificacionNoLeida).
+		Update("status", models.NotificacionLeida)
+	if err := errorGorm(resultado.Error, "actualizar las notificaciones"); err != nil {
+		return 0, err
+	}
+	return int(resultado.RowsAffected), nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"ProyectoIngeso/models"
+	"context"
+	"errors"
+)
+
+// ErrNoEncontrado indica que el registro pedido no existe.
+var ErrNoEncontrado = errors.New("registro no encontrado")
+
+// ErrDuplicado indica que el registro choca con una restricción de unicidad.
+var ErrDuplicado = errors.New("registro duplicado")
+
+// UserRepository guarda los usuarios. Las búsquedas de un solo usuario
+// devuelven ErrNoEncontrado si no existe.
+type UserRepository interface {
+	PorID(ctx context.Context, userID string) (*models.Usuario, error)
+	PorEmail(ctx context.Context, email string) (*models.Usuario, error)
+	PorUsername(ctx context.Context, username string) (*models.Usuario, error)
+	// PorIdentificador busca por email o por username, como en el inicio de sesión.
+	PorIdentificador(ctx context.Context, identificador string) (*models.Usuario, error)
+	Todos(ctx context.Context) ([]models.Usuario, error)
+
+	// UsernameEnUso y EmailEnUso ignoran al usuario excluirID, para poder
+	// verificar un cambio sin chocar con el valor actual del propio usuario.
+	UsernameEnUso(ctx context.Context, username string, excluirID string) (bool, error)
+	EmailEnUso(ctx context.Context, email string, excluirID string) (bool, error)
+
+	Crear(ctx context.Context, usuario *models.Usuario) error
+	// Actualizar escribe solo los campos no nulos de cambios y los refleja en usuario.
+	Actualizar(ctx context.Context, usuario *models.Usuario, cambios CambiosUsuario) error
+	Eliminar(ctx context.Context, userID string) error
+}
+
+// CambiosUsuario lista los campos a modificar de un usuario. Escribir solo
+// lo que cambia evita pisar modificaciones concurrentes de otros campos.
+type CambiosUsuario struct {
+	Username     *string
+	Email        *string
+	Password     *string
+	NameLastName *string
+	Role         *string
+}
+
+// CartRepository guarda los items de los carritos. Los items de un usuario
+// se ordenan por cartID.
+type CartRepository interface {
+	PorID(ctx context.Context, cartID string) (*models.Carrito, error)
+	Items(ctx context.Context, userID string) ([]models.Carrito, error)
+	// Pagina devuelve hasta limite items con cartID mayor que despuesDe.
+	Pagina(ctx context.Context, userID string, despuesDe string, limite int) ([]models.Carrito, error)
+	Contar(ctx context.Context, userID string) (int, error)
+	Contiene(ctx context.Context, userID string, courseID string) (bool, error)
+	// UsuariosConCurso devuelve los userID cuyo carrito tiene el curso.
+	UsuariosConCurso(ctx context.Context, courseID string) ([]string, error)
+
+	Agregar(ctx context.Context, item *models.Carrito) error
+	Eliminar(ctx context.Context, cartID string) error
+	// Quitar elimina los cursos indicados del carrito del usuario.
+	Quitar(ctx context.Context, userID string, courseIDs ...string) error
+	QuitarCursoDeTodos(ctx context.Context, courseID string) error
+	Vaciar(ctx context.Context, userID string) error
+}
+
+// EnrollmentRepository guarda las inscripciones de los usuarios a cursos.
+type EnrollmentRepository interface {
+	EstaInscrito(ctx context.Context, userID string, courseID string) (bool, error)
+	// DeUsuario devuelve las inscripciones del usuario en orden de inscripción.
+	DeUsuario(ctx context.Context, userID string) ([]models.UsuarioCurso, error)
+	Crear(ctx context.Context, inscripcion *models.UsuarioCurso) error
+	// RetirarPorPago elimina las inscripciones que creó un pago del usuario.
+	RetirarPorPago(ctx context.Context, userID string, paymentID string) error
+}
+
+// PaymentRepository guarda los pagos junto con sus items. Los pagos se
+// devuelven siempre con sus items.
+type PaymentRepository interface {
+	PorID(ctx context.Context, paymentID string) (*models.Pago, error)
+	// PorReferencia busca el pago por su ID y el de su intención en la
+	// pasarela, como llegan en los webhooks.
+	PorReferencia(ctx context.Context, paymentID string, gatewayRef string) (*models.Pago, error)
+	// DeUsuario devuelve los pagos del usuario, del más reciente al más antiguo.
+	DeUsuario(ctx context.Context, userID string) ([]models.Pago, error)
+	ContarPendientes(ctx context.Context, userID string) (int, error)
+
+	// Crear guarda el pago con sus items.
+	Crear(ctx context.Context, pago *models.Pago) error
+	CambiarEstado(ctx context.Context, paymentID string, estado string) error
+	AsignarReferencia(ctx context.Context, paymentID string, gatewayRef string) error
+}
+
+// ReviewRepository guarda las reseñas de los cursos. Cada usuario tiene a
+// lo sumo una reseña por curso.
+type ReviewRepository interface {
+	PorID(ctx context.Context, reviewID string) (*models.Reseña, error)
+	// DeCurso devuelve las reseñas del curso, de la más reciente a la más antigua.
+	DeCurso(ctx context.Context, courseID string) ([]models.Reseña, error)
+	Existe(ctx context.Context, userID string, courseID string) (bool, error)
+	// ContarPorCalificacion cuenta las reseñas del curso por calificación.
+	ContarPorCalificacion(ctx context.Context, courseID string) (map[int]int, error)
+
+	Crear(ctx context.Context, resena *models.Reseña) error
+	// Editar escribe la calificación, los comentarios y la fecha de
+	// modificación; Responder, la respuesta y su fecha. Cada una escribe solo
+	// sus campos para no pisar a la otra.
+	Editar(ctx context.Context, resena *models.Reseña) error
+	Responder(ctx context.Context, resena *models.Reseña) error
+	Eliminar(ctx context.Context, reviewID string) error
+}
+
+// NotificationRepository guarda las notificaciones de los usuarios. Se
+// listan de la más reciente a la más antigua, desempatando por ID.
+type NotificationRepository interface {
+	PorID(ctx context.Context, notificationID string) (*models.Notificación, error)
+	// Pagina devuelve hasta limite notificaciones del usuario en estado, o
+	// en cualquier estado si está vacío. Si antesDeID no está vacío, solo
+	// las que van después de (antesDeFecha, antesDeID) en el listado.
+	Pagina(ctx context.Context, userID string, estado string, antesDeFecha string, antesDeID string, limite int) ([]models.Notificación, error)
+	ContarNoLeidas(ctx context.Context, userID string) (int, error)
+
+	Crear(ctx context.Context, notificacion *models.Notificación) error
+	MarcarLeida(ctx context.Context, notificationID string) error
+	// MarcarTodasLeidas devuelve cuántas notificaciones cambiaron.
+	MarcarTodasLeidas(ctx context.Context, userID string) (int, error)
+}
+
+// Repositorios agrupa los repositorios que comparten una misma conexión o
+// transacción.
+type Repositorios struct {
+	Usuarios       UserRepository
+	Carritos       CartRepository
+	Inscripciones  EnrollmentRepository
+	Pagos          PaymentRepository
+	Resenas        ReviewRepository
+	Notificaciones NotificationRepository
+}
+
+// Store da acceso a los repositorios, fuera o dentro de una transacción.
+type Store interface {
+	Repositorios() Repositorios
+	// EnTransaccion ejecuta fn con repositorios que comparten una
+	// transacción. Si fn devuelve error no queda ningún cambio aplicado.
+	// fn puede ejecutarse más de una vez, así que no debe tener otros efectos.
+	EnTransaccion(ctx context.Context, fn func(repos Repositorios) error) error
+}
@@ -0,0 +1,456 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"ProyectoIngeso/database/dbtest"
+	"ProyectoIngeso/migrations"
+	"ProyectoIngeso/models"
+	"ProyectoIngeso/repository"
+
+	"gorm.io/gorm"
+)
+
+// entorno es un Store vacío.
+type entorno struct {
+	store repository.Store
+}
+
+// paraCadaStore corre prueba contra FakeStore y contra GormStore en cada
+// motor, para que el fake siga cumpliendo las mismas reglas que las tablas.
+func paraCadaStore(t *testing.T, prueba func(t *testing.T, e entorno)) {
+	t.Run("fake", func(t *testing.T) {
+		prueba(t, entorno{store: repository.NewFakeStore()})
+	})
+	dbtest.ParaCadaMotor(t, func(t *testing.T, db *gorm.DB) {
+		if _, err := migrations.Subir(db); err != nil {
+			t.Fatal(err)
+		}
+		prueba(t, entorno{store: repository.NewGormStore(db)})
+	})
+}
+
+func crearUsuario(t *testing.T, repos repository.Repositorios, id string) *models.Usuario {
+	t.Helper()
+	usuario := &models.Usuario{UserID: id, Username: id, Email: id + "@ejemplo.com", Role: models.RolUsuario}
+	if err := repos.Usuarios.Crear(context.Background(), usuario); err != nil {
+		t.Fatal(err)
+	}
+	return usuario
+}
+
+// crearPago registra un pago aprobado con un item por curso.
+func crearPago(t *testing.T, repos repository.Repositorios, paymentID string, userID string, fecha string, cursos ...string) *models.Pago {
+	t.Helper()
+	pago := &models.Pago{PaymentID: paymentID, UserID: userID, Status: models.EstadoPagoAprobado, PaymentDate: fecha}
+	for _, courseID := range cursos {
+		pago.Items = append(pago.Items, models.PagoItem{ItemID: paymentID + "-" + courseID, PaymentID: paymentID, CourseID: courseID, Price: 10})
+		pago.Amount += 10
+	}
+	if err := repos.Pagos.Crear(context.Background(), pago); err != nil {
+		t.Fatal(err)
+	}
+	return pago
+}
+
+func courseIDs(items []models.Carrito) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.CourseID)
+	}
+	return ids
+}
+
+func TestUsuarios(t *testing.T) {
+	paraCadaStore(t, func(t *testing.T, e entorno) {
+		ctx := context.Background()
+		repos := e.store.Repositorios()
+		ana := crearUsuario(t, repos, "ana")
+		crearUsuario(t, repos, "beto")
+
+		for _, repetido := range []models.Usuario{
+			{UserID: "otro1", Username: "otro1", Email: ana.Email},
+			{UserID: "otro2", Username: ana.Username, Email: "otro2@ejemplo.com"},
+			{UserID: ana.UserID, Username: "otro3", Email: "otro3@ejemplo.com"},
+		} {
+			if err := repos.Usuarios.Crear(ctx, &repetido); !errors.Is(err, repository.ErrDuplicado) {
+				t.Errorf("Crear(%+v) = %v, se esperaba ErrDuplicado", repetido, err)
+			}
+		}
+
+		for _, identificador := range []string{ana.Email, ana.Username} {
+			u, err := repos.Usuarios.PorIdentificador(ctx, identificador)
+			if err != nil || u.UserID != ana.UserID {
+				t.Errorf("PorIdentificador(%s) = %v, %v", identificador, u, err)
+			}
+		}
+		if _, err := repos.Usuarios.PorID(ctx, "nadie"); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Errorf("PorID de un usuario inexistente = %v", err)
+		}
+
+		if enUso, err := repos.Usuarios.EmailEnUso(ctx, ana.Email, ana.UserID); err != nil || enUso {
+			t.Errorf("EmailEnUso del propio email = %v, %v", enUso, err)
+		}
+		if enUso, err := repos.Usuarios.UsernameEnUso(ctx, "beto", ana.UserID); err != nil || !enUso {
+			t.Errorf("UsernameEnUso de otro usuario = %v, %v", enUso, err)
+		}
+
+		emailDeBeto := "beto@ejemplo.com"
+		if err := repos.Usuarios.Actualizar(ctx, ana, repository.CambiosUsuario{Email: &emailDeBeto}); !errors.Is(err, repository.ErrDuplicado) {
+			t.Errorf("Actualizar al email de otro usuario = %v", err)
+		}
+		if ana.Email != "ana@ejemplo.com" {
+			t.Errorf("un cambio rechazado modificó el usuario: %s", ana.Email)
+		}
+
+		nombre := "Ana Pérez"
+		if err := repos.Usuarios.Actualizar(ctx, ana, repository.CambiosUsuario{NameLastName: &nombre}); err != nil {
+			t.Fatal(err)
+		}
+		guardado, err := repos.Usuarios.PorID(ctx, ana.UserID)
+		if err != nil || guardado.NameLastName != nombre || guardado.Email != ana.Email {
+			t.Errorf("usuario tras Actualizar = %+v, %v", guardado, err)
+		}
+	})
+}
+
+func TestCarritos(t *testing.T) {
+	paraCadaStore(t, func(t *testing.T, e entorno) {
+		ctx := context.Background()
+		repos := e.store.Repositorios()
+		crearUsuario(t, repos, "ana")
+		crearUsuario(t, repos, "beto")
+
+		for _, item := range []models.Carrito{
+			{CartID: "a3", UserID: "ana", CourseID: "c3"},
+			{CartID: "a1", UserID: "ana", CourseID: "c1"},
+			{CartID: "a2", UserID: "ana", CourseID: "c2"},
+			{CartID: "b1", UserID: "beto", CourseID: "c1"},
+		} {
+			if err := repos.Carritos.Agregar(ctx, &item); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := repos.Carritos.Agregar(ctx, &models.Carrito{CartID: "a4", UserID: "ana", CourseID: "c1"}); !errors.Is(err, repository.ErrDuplicado) {
+			t.Errorf("Agregar un curso repetido = %v, se esperaba ErrDuplicado", err)
+		}
+
+		pagina, err := repos.Carritos.Pagina(ctx, "ana", "", 2)
+		if err != nil || !slices.Equal(courseIDs(pagina), []string{"c1", "c2"}) {
+			t.Errorf("primera página = %v, %v", courseIDs(pagina), err)
+		}
+		pagina, err = repos.Carritos.Pagina(ctx, "ana", "a2", 2)
+		if err != nil || !slices.Equal(courseIDs(pagina), []string{"c3"}) {
+			t.Errorf("segunda página = %v, %v", courseIDs(pagina), err)
+		}
+
+		usuarios, err := repos.Carritos.UsuariosConCurso(ctx, "c1")
+		slices.Sort(usuarios)
+		if err != nil || !slices.Equal(usuarios, []string{"ana", "beto"}) {
+			t.Errorf("UsuariosConCurso = %v, %v", usuarios, err)
+		}
+
+		if err := repos.Carritos.Quitar(ctx, "ana", "c2", "c3"); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Carritos.QuitarCursoDeTodos(ctx, "c1"); err != nil {
+			t.Fatal(err)
+		}
+		for _, userID := range []string{"ana", "beto"} {
+			if n, err := repos.Carritos.Contar(ctx, userID); err != nil || n != 0 {
+				t.Errorf("carrito de %s = %d items, %v", userID, n, err)
+			}
+		}
+	})
+}
+
+func TestInscripciones(t *testing.T) {
+	paraCadaStore(t, func(t *testing.T, e entorno) {
+		ctx := context.Background()
+		repos := e.store.Repositorios()
+		crearUsuario(t, repos, "ana")
+		crearPago(t, repos, "p1", "ana", "2024-05-02T00:00:00Z", "c2")
+
+		pago := "p1"
+		for _, inscripcion := range []models.UsuarioCurso{
+			{ID: "i2", UserID: "ana", CourseID: "c2", EnrolledAt: "2024-05-02T00:00:00Z", Source: models.InscripcionCompra, PaymentID: &pago},
+			{ID: "i1", UserID: "ana", CourseID: "c1", EnrolledAt: "2024-05-01T00:00:00Z", Source: models.InscripcionAdmin},
+		} {
+			if err := repos.Inscripciones.Crear(ctx, &inscripcion); err != nil {
+				t.Fatal(err)
+			}
+		}
+		repetida := models.UsuarioCurso{ID: "i3", UserID: "ana", CourseID: "c1", EnrolledAt: "2024-05-03T00:00:00Z", Source: models.InscripcionRegalo}
+		if err := repos.Inscripciones.Crear(ctx, &repetida); !errors.Is(err, repository.ErrDuplicado) {
+			t.Errorf("Crear una inscripción repetida = %v, se esperaba ErrDuplicado", err)
+		}
+
+		inscripciones, err := repos.Inscripciones.DeUsuario(ctx, "ana")
+		if err != nil || len(inscripciones) != 2 || inscripciones[0].ID != "i1" || inscripciones[1].ID != "i2" {
+			t.Fatalf("DeUsuario = %+v, %v", inscripciones, err)
+		}
+
+		if err := repos.Inscripciones.RetirarPorPago(ctx, "ana", "p1"); err != nil {
+			t.Fatal(err)
+		}
+		if inscrito, err := repos.Inscripciones.EstaInscrito(ctx, "ana", "c2"); err != nil || inscrito {
+			t.Errorf("la inscripción del pago sigue vigente: %v, %v", inscrito, err)
+		}
+		if inscrito, err := repos.Inscripciones.EstaInscrito(ctx, "ana", "c1"); err != nil || !inscrito {
+			t.Errorf("RetirarPorPago quitó una inscripción sin pago: %v, %v", inscrito, err)
+		}
+	})
+}
+
+func TestPagos(t *testing.T) {
+	paraCadaStore(t, func(t *testing.T, e entorno) {
+		ctx := context.Background()
+		repos := e.store.Repositorios()
+		crearUsuario(t, repos, "ana")
+		crearPago(t, repos, "p1", "ana", "2024-05-01T00:00:00Z", "c1")
+		crearPago(t, repos, "p2", "ana", "2024-05-02T00:00:00Z", "c2", "c3")
+
+		repetido := models.Pago{PaymentID: "p1", UserID: "ana", Status: models.EstadoPagoPendiente}
+		if err := repos.Pagos.Crear(ctx, &repetido); !errors.Is(err, repository.ErrDuplicado) {
+			t.Errorf("Crear un pago repetido = %v, se esperaba ErrDuplicado", err)
+		}
+		cursoRepetido := models.Pago{PaymentID: "p3", UserID: "ana", Status: models.EstadoPagoPendiente, Items: []models.PagoItem{
+			{ItemID: "p3-a", PaymentID: "p3", CourseID: "c1"},
+			{ItemID: "p3-b", PaymentID: "p3", CourseID: "c1"},
+		}}
+		if err := repos.Pagos.Crear(ctx, &cursoRepetido); !errors.Is(err, repository.ErrDuplicado) {
+			t.Errorf("Crear un pago con un curso repetido = %v, se esperaba ErrDuplicado", err)
+		}
+
+		pagos, err := repos.Pagos.DeUsuario(ctx, "ana")
+		if err != nil || len(pagos) != 2 || pagos[0].PaymentID != "p2" || pagos[1].PaymentID != "p1" {
+			t.Fatalf("DeUsuario = %+v, %v", pagos, err)
+		}
+		if len(pagos[0].Items) != 2 || pagos[0].Amount != 20 {
+			t.Errorf("pago sin sus items: %+v", pagos[0])
+		}
+
+		if err := repos.Pagos.CambiarEstado(ctx, "p2", models.EstadoPagoPendiente); err != nil {
+			t.Fatal(err)
+		}
+		if n, err := repos.Pagos.ContarPendientes(ctx, "ana"); err != nil || n != 1 {
+			t.Errorf("ContarPendientes = %d, %v", n, err)
+		}
+		if err := repos.Pagos.AsignarReferencia(ctx, "p2", "pi_1"); err != nil {
+			t.Fatal(err)
+		}
+		pago, err := repos.Pagos.PorReferencia(ctx, "p2", "pi_1")
+		if err != nil || pago.Status != models.EstadoPagoPendiente || len(pago.Items) != 2 {
+			t.Errorf("PorReferencia = %+v, %v", pago, err)
+		}
+		if _, err := repos.Pagos.PorReferencia(ctx, "p1", "pi_1"); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Errorf("PorReferencia con la referencia de otro pago = %v", err)
+		}
+
+		for nombre, err := range map[string]error{
+			"PorID":             func() error { _, err := repos.Pagos.PorID(ctx, "nadie"); return err }(),
+			"CambiarEstado":     repos.Pagos.CambiarEstado(ctx, "nadie", models.EstadoPagoAprobado),
+			"AsignarReferencia": repos.Pagos.AsignarReferencia(ctx, "nadie", "pi_2"),
+		} {
+			if !errors.Is(err, repository.ErrNoEncontrado) {
+				t.Errorf("%s de un pago inexistente = %v, se esperaba ErrNoEncontrado", nombre, err)
+			}
+		}
+	})
+}
+
+func TestResenas(t *testing.T) {
+	paraCadaStore(t, func(t *testing.T, e entorno) {
+		ctx := context.Background()
+		repos := e.store.Repositorios()
+		crearUsuario(t, repos, "ana")
+		crearUsuario(t, repos, "beto")
+
+		for _, resena := range []models.Reseña{
+			{ReviewID: "r1", UserID: "ana", CourseID: "c1", Rating: 4, CreatedAt: "2024-05-01T00:00:00Z"},
+			{ReviewID: "r2", UserID: "beto", CourseID: "c1", Rating: 4, CreatedAt: "2024-05-02T00:00:00Z"},
+			{ReviewID: "r3", UserID: "ana", CourseID: "c2", Rating: 1, CreatedAt: "2024-05-03T00:00:00Z"},
+		} {
+			if err := repos.Resenas.Crear(ctx, &resena); err != nil {
+				t.Fatal(err)
+			}
+		}
+		repetida := models.Reseña{ReviewID: "r4", UserID: "ana", CourseID: "c1", Rating: 5}
+		if err := repos.Resenas.Crear(ctx, &repetida); !errors.Is(err, repository.ErrDuplicado) {
+			t.Errorf("Crear una segunda reseña del mismo curso = %v, se esperaba ErrDuplicado", err)
+		}
+
+		if existe, err := repos.Resenas.Existe(ctx, "beto", "c2"); err != nil || existe {
+			t.Errorf("Existe sin reseña = %v, %v", existe, err)
+		}
+		resenas, err := repos.Resenas.DeCurso(ctx, "c1")
+		if err != nil || len(resenas) != 2 || resenas[0].ReviewID != "r2" || resenas[1].ReviewID != "r1" {
+			t.Errorf("DeCurso = %+v, %v", resenas, err)
+		}
+
+		// Editar y Responder no se pisan entre sí
+		if err := repos.Resenas.Responder(ctx, &models.Reseña{ReviewID: "r1", Reply: "gracias", RepliedAt: "2024-05-04T00:00:00Z"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Resenas.Editar(ctx, &models.Reseña{ReviewID: "r1", Rating: 5, Comments: "mejor", UpdatedAt: "2024-05-05T00:00:00Z"}); err != nil {
+			t.Fatal(err)
+		}
+		resena, err := repos.Resenas.PorID(ctx, "r1")
+		if err != nil || resena.Rating != 5 || resena.Comments != "mejor" || resena.Reply != "gracias" || resena.CreatedAt != "2024-05-01T00:00:00Z" {
+			t.Errorf("reseña tras Responder y Editar = %+v, %v", resena, err)
+		}
+
+		conteo, err := repos.Resenas.ContarPorCalificacion(ctx, "c1")
+		if err != nil || len(conteo) != 2 || conteo[4] != 1 || conteo[5] != 1 {
+			t.Errorf("ContarPorCalificacion = %v, %v", conteo, err)
+		}
+
+		if err := repos.Resenas.Eliminar(ctx, "r1"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repos.Resenas.PorID(ctx, "r1"); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Errorf("PorID de una reseña eliminada = %v", err)
+		}
+		if err := repos.Resenas.Editar(ctx, &models.Reseña{ReviewID: "r1"}); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Errorf("Editar una reseña eliminada = %v", err)
+		}
+	})
+}
+
+func TestNotificaciones(t *testing.T) {
+	paraCadaStore(t, func(t *testing.T, e entorno) {
+		ctx := context.Background()
+		repos := e.store.Repositorios()
+		crearUsuario(t, repos, "ana")
+		crearUsuario(t, repos, "beto")
+
+		// n2 y n3 comparten fecha: el ID desempata
+		for _, notificacion := range []models.Notificación{
+			{NotificationID: "n1", UserID: "ana", Status: models.NotificacionNoLeida, CreatedAt: "2024-05-01T00:00:00Z"},
+			{NotificationID: "n2", UserID: "ana", Status: models.NotificacionLeida, CreatedAt: "2024-05-02T00:00:00Z"},
+			{NotificationID: "n3", UserID: "ana", Status: models.NotificacionNoLeida, CreatedAt: "2024-05-02T00:00:00Z"},
+			{NotificationID: "n4", UserID: "beto", Status: models.NotificacionNoLeida, CreatedAt: "2024-05-03T00:00:00Z"},
+		} {
+			if err := repos.Notificaciones.Crear(ctx, &notificacion); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		ids := func(notificaciones []models.Notificación) []string {
+			var ids []string
+			for _, n := range notificaciones {
+				ids = append(ids, n.NotificationID)
+			}
+			return ids
+		}
+		pagina, err := repos.Notificaciones.Pagina(ctx, "ana", "", "", "", 2)
+		if err != nil || !slices.Equal(ids(pagina), []string{"n3", "n2"}) {
+			t.Errorf("primera página = %v, %v", ids(pagina), err)
+		}
+		pagina, err = repos.Notificaciones.Pagina(ctx, "ana", "", "2024-05-02T00:00:00Z", "n3", 2)
+		if err != nil || !slices.Equal(ids(pagina), []string{"n2", "n1"}) {
+			t.Errorf("página tras n3 = %v, %v", ids(pagina), err)
+		}
+		pagina, err = repos.Notificaciones.Pagina(ctx, "ana", models.NotificacionNoLeida, "", "", 10)
+		if err != nil || !slices.Equal(ids(pagina), []string{"n3", "n1"}) {
+			t.Errorf("no leídas = %v, %v", ids(pagina), err)
+		}
+
+		if err := repos.Notificaciones.MarcarLeida(ctx, "n1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Notificaciones.MarcarLeida(ctx, "nadie"); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Errorf("MarcarLeida de una notificación inexistente = %v", err)
+		}
+		if n, err := repos.Notificaciones.ContarNoLeidas(ctx, "ana"); err != nil || n != 1 {
+			t.Errorf("ContarNoLeidas = %d, %v", n, err)
+		}
+		if n, err := repos.Notificaciones.MarcarTodasLeidas(ctx, "ana"); err != nil || n != 1 {
+			t.Errorf("MarcarTodasLeidas = %d, %v", n, err)
+		}
+		if n, err := repos.Notificaciones.ContarNoLeidas(ctx, "beto"); err != nil || n != 1 {
+			t.Errorf("MarcarTodasLeidas cambió las de otro usuario: %d, %v", n, err)
+		}
+	})
+}
+
+func TestEliminarUsuarioBorraSusDatos(t *testing.T) {
+	paraCadaStore(t, func(t *testing.T, e entorno) {
+		ctx := context.Background()
+		repos := e.store.Repositorios()
+		crearUsuario(t, repos, "ana")
+		crearUsuario(t, repos, "beto")
+		for _, userID := range []string{"ana", "beto"} {
+			if err := repos.Carritos.Agregar(ctx, &models.Carrito{CartID: userID + "-c1", UserID: userID, CourseID: "c1"}); err != nil {
+				t.Fatal(err)
+			}
+			inscripcion := models.UsuarioCurso{ID: userID + "-c2", UserID: userID, CourseID: "c2", EnrolledAt: "2024-05-01T00:00:00Z", Source: models.InscripcionAdmin}
+			if err := repos.Inscripciones.Crear(ctx, &inscripcion); err != nil {
+				t.Fatal(err)
+			}
+			crearPago(t, repos, userID+"-p1", userID, "2024-05-01T00:00:00Z", "c3")
+			if err := repos.Resenas.Crear(ctx, &models.Reseña{ReviewID: userID + "-r1", UserID: userID, CourseID: "c2", Rating: 5}); err != nil {
+				t.Fatal(err)
+			}
+			if err := repos.Notificaciones.Crear(ctx, &models.Notificación{NotificationID: userID + "-n1", UserID: userID, Status: models.NotificacionNoLeida}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := repos.Usuarios.Eliminar(ctx, "ana"); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Usuarios.Eliminar(ctx, "ana"); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Errorf("Eliminar dos veces = %v, se esperaba ErrNoEncontrado", err)
+		}
+		if n, err := repos.Carritos.Contar(ctx, "ana"); err != nil || n != 0 {
+			t.Errorf("quedaron %d items en el carrito del usuario eliminado: %v", n, err)
+		}
+		if inscripciones, err := repos.Inscripciones.DeUsuario(ctx, "ana"); err != nil || len(inscripciones) != 0 {
+			t.Errorf("quedaron inscripciones del usuario eliminado: %+v, %v", inscripciones, err)
+		}
+		if pagos, err := repos.Pagos.DeUsuario(ctx, "ana"); err != nil || len(pagos) != 0 {
+			t.Errorf("quedaron pagos del usuario eliminado: %+v, %v", pagos, err)
+		}
+		if existe, err := repos.Resenas.Existe(ctx, "ana", "c2"); err != nil || existe {
+			t.Errorf("quedó la reseña del usuario eliminado: %v, %v", existe, err)
+		}
+		if _, err := repos.Notificaciones.PorID(ctx, "ana-n1"); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Errorf("quedó la notificación del usuario eliminado: %v", err)
+		}
+		if n, err := repos.Carritos.Contar(ctx, "beto"); err != nil || n != 1 {
+			t.Errorf("se borró el carrito de otro usuario: %d, %v", n, err)
+		}
+		if pagos, err := repos.Pagos.DeUsuario(ctx, "beto"); err != nil || len(pagos) != 1 {
+			t.Errorf("se borraron los pagos de otro usuario: %+v, %v", pagos, err)
+		}
+	})
+}
+
+func TestEnTransaccionRevierteAlFallar(t *testing.T) {
+	paraCadaStore(t, func(t *testing.T, e entorno) {
+		ctx := context.Background()
+		errFn := errors.New("falla")
+		err := e.store.EnTransaccion(ctx, func(repos repository.Repositorios) error {
+			crearUsuario(t, repos, "ana")
+			if err := repos.Carritos.Agregar(ctx, &models.Carrito{CartID: "a1", UserID: "ana", CourseID: "c1"}); err != nil {
+				return err
+			}
+			return errFn
+		})
+		if !errors.Is(err, errFn) {
+			t.Fatalf("EnTransaccion = %v", err)
+		}
+
+		repos := e.store.Repositorios()
+		if _, err := repos.Usuarios.PorID(ctx, "ana"); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Errorf("el usuario de la transacción fallida quedó guardado: %v", err)
+		}
+		if n, err := repos.Carritos.Contar(ctx, "ana"); err != nil || n != 0 {
+			t.Errorf("el carrito de la transacción fallida quedó guardado: %d, %v", n, err)
+		}
+	})
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"ProyectoIngeso/courses"
+	"ProyectoIngeso/models"
+	"ProyectoIngeso/repository"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ErrItemNoEncontrado indica que el item del carrito no existe.
+var ErrItemNoEncontrado = errors.New("carrito no encontrado")
+
+// CartService maneja los carritos de los usuarios.
+type CartService struct {
+	store    repository.Store
+	catalogo courses.Catalog
+}
+
+// PaginaCarrito es una página del carrito. Total cuenta el carrito completo.
+type PaginaCarrito struct {
+	Items        []models.Carrito
+	Total        int
+	HaySiguiente bool
+}
+
+// Agregar pone el curso en el carrito del usuario si el curso existe y el
+// usuario no lo tiene ni está ya en su carrito. Las verificaciones y la
+// inserción van en la misma transacción para no duplicar el item.
+func (s *CartService) Agregar(ctx context.Context, userID string, courseID string) (*models.Carrito, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID no puede estar vacío")
+	}
+	if err := verificarCurso(ctx, s.catalogo, courseID); err != nil {
+		return nil, err
+	}
+
+	item := &models.Carrito{
+		CartID:   uuid.NewString(),
+		UserID:   userID,
+		CourseID: courseID,
+	}
+
+	err := s.store.EnTransaccion(ctx, func(repos repository.Repositorios) error {
+		inscrito, err := repos.Inscripciones.EstaInscrito(ctx, userID, courseID)
+		if err != nil {
+			return err
+		}
+		if inscrito {
+			return fmt.Errorf("el usuario ya tiene este curso en su lista de cursos")
+		}
+
+		enCarrito, err := repos.Carritos.Contiene(ctx, userID, courseID)
+		if err != nil {
+			return err
+		}
+		if enCarrito {
+			return fmt.Errorf("el curso ya está en tu carrito")
+		}
+
+		return repos.Carritos.Agregar(ctx, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// Quitar saca el curso del carrito del usuario.
+func (s *CartService) Quitar(ctx context.Context, userID string, courseID string) error {
+	if err := verificarCurso(ctx, s.catalogo, courseID); err != nil {
+		return err
+	}
+	return s.store.Repositorios().Carritos.Quitar(ctx, userID, courseID)
+}
+
+// QuitarCursoDeTodos saca el curso de todos los carritos y devuelve los
+// usuarios afectados. La consulta y el borrado van en la misma transacción
+// para no omitir carritos agregados entre ambos.
+func (s *CartService) QuitarCursoDeTodos(ctx context.Context, courseID string) ([]string, error) {
+	if err := verificarCurso(ctx, s.catalogo, courseID); err != nil {
+		return nil, err
+	}
+
+	var userIDs []string
+	err := s.store.EnTransaccion(ctx, func(repos repository.Repositorios) error {
+		var err error
+		if userIDs, err = repos.Carritos.UsuariosConCurso(ctx, courseID); err != nil {
+			return err
+		}
+		if err := repos.Carritos.QuitarCursoDeTodos(ctx, courseID); err != nil {
+			return errors.New("no se pudo eliminar los carritos con el curso especificado")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+// Item devuelve un item del carrito por su cartID.
+func (s *CartService) Item(ctx context.Context, cartID string) (*models.Carrito, error) {
+	item, err := s.store.Repositorios().Carritos.PorID(ctx, cartID)
+	if errors.Is(err, repository.ErrNoEncontrado) {
+		return nil, ErrItemNoEncontrado
+	}
+	return item, err
+}
+
+// EliminarItem borra un item del carrito por su cartID.
+func (s *CartService) EliminarItem(ctx context.Context, cartID string) error {
+	if err := s.store.Repositorios().Carritos.Eliminar(ctx, cartID); err != nil {
+		return errors.New("no se pudo eliminar el carrito")
+	}
+	return nil
+}
+
+// Items devuelve el carrito completo del usuario.
+func (s *CartService) Items(ctx context.Context, userID string) ([]models.Carrito, error) {
+	return s.store.Repositorios().Carritos.Items(ctx, userID)
+}
+
+// CourseIDs devuelve los cursos que el usuario tiene en su carrito.
+func (s *CartService) CourseIDs(ctx context.Context, userID string) ([]string, error) {
+	items, err := s.Items(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	courseIDs := make([]string, 0, len(items))
+	for _, item := range items {
+		courseIDs = append(courseIDs, item.CourseID)
+	}
+	return courseIDs, nil
+}
+
+// Pagina devuelve hasta limite items con cartID mayor que despuesDe.
+func (s *CartService) Pagina(ctx context.Context, userID string, despuesDe string, limite int) (*PaginaCarrito, error) {
+	carritos := s.store.Repositorios().Carritos
+
+	total, err := carritos.Contar(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Se pide un elemento extra para saber si hay otra página
+	items, err := carritos.Pagina(ctx, userID, despuesDe, limite+1)
+	if err != nil {
+		return nil, err
+	}
+
+	pagina := &PaginaCarrito{Items: items, Total: total, HaySiguiente: len(items) > limite}
+	if pagina.HaySiguiente {
+		pagina.Items = items[:limite]
+	}
+	return pagina, nil
+}
+
+// Vaciar elimina todos los items del carrito del usuario.
+func (s *CartService) Vaciar(ctx context.Context, userID string) error {
+	return s.store.Repositorios().Carritos.Vaciar(ctx, userID)
+}
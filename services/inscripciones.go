@@ -0,0 +1,76 @@
+package services
+
+import (
+	"ProyectoIngeso/courses"
+	"ProyectoIngeso/models"
+	"ProyectoIngeso/repository"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EnrollmentService maneja las inscripciones de los usuarios a cursos.
+type EnrollmentService struct {
+	store    repository.Store
+	catalogo courses.Catalog
+}
+
+// Inscribir agrega el curso a la lista del usuario si el curso existe y el
+// usuario no lo tiene. La verificación y la creación van en la misma
+// transacción.
+func (s *EnrollmentService) Inscribir(ctx context.Context, userID string, courseID string, origen string) (*models.UsuarioCurso, error) {
+	if err := verificarCurso(ctx, s.catalogo, courseID); err != nil {
+		return nil, err
+	}
+
+	var inscripcion *models.UsuarioCurso
+	err := s.store.EnTransaccion(ctx, func(repos repository.Repositorios) error {
+		inscrito, err := repos.Inscripciones.EstaInscrito(ctx, userID, courseID)
+		if err != nil {
+			return err
+		}
+		if inscrito {
+			return fmt.Errorf("el usuario ya tiene este curso agregado")
+		}
+
+		inscripcion, err = CrearInscripcion(ctx, repos.Inscripciones, userID, courseID, origen, nil)
+		if err != nil {
+			return fmt.Errorf("error al agregar el curso al usuario: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inscripcion, nil
+}
+
+// EstaInscrito indica si el usuario ya tiene el curso.
+func (s *EnrollmentService) EstaInscrito(ctx context.Context, userID string, courseID string) (bool, error) {
+	return s.store.Repositorios().Inscripciones.EstaInscrito(ctx, userID, courseID)
+}
+
+// DeUsuario devuelve las inscripciones del usuario, en orden de inscripción.
+func (s *EnrollmentService) DeUsuario(ctx context.Context, userID string) ([]models.UsuarioCurso, error) {
+	return s.store.Repositorios().Inscripciones.DeUsuario(ctx, userID)
+}
+
+// CrearInscripcion crea la inscripción con inscripciones, que puede pertenecer a
+// una transacción abierta por quien llama. paymentID solo se indica cuando
+// la inscripción viene de una compra.
+func CrearInscripcion(ctx context.Context, inscripciones repository.EnrollmentRepository, userID string, courseID string, origen string, paymentID *string) (*models.UsuarioCurso, error) {
+	inscripcion := &models.UsuarioCurso{
+		ID:         uuid.NewString(),
+		UserID:     userID,
+		CourseID:   courseID,
+		EnrolledAt: time.Now().UTC().Format(time.RFC3339),
+		Source:     origen,
+		PaymentID:  paymentID,
+	}
+	if err := inscripciones.Crear(ctx, inscripcion); err != nil {
+		return nil, err
+	}
+	return inscripcion, nil
+}
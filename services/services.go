@@ -0,0 +1,42 @@
+package services
+
+import (
+	"ProyectoIngeso/courses"
+	"ProyectoIngeso/repository"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Servicios agrupa las reglas de negocio de usuarios, carritos e
+// inscripciones. Los usan tanto los resolvers GraphQL como el consumidor de
+// RabbitMQ; la autorización y la publicación de eventos quedan a cargo de
+// quien los llama.
+type Servicios struct {
+	Usuarios      *UserService
+	Carritos      *CartService
+	Inscripciones *EnrollmentService
+}
+
+// New arma los servicios sobre store. catalogo se usa para verificar que
+// los cursos existan.
+func New(store repository.Store, catalogo courses.Catalog) Servicios {
+	return Servicios{
+		Usuarios:      &UserService{store: store},
+		Carritos:      &CartService{store: store, catalogo: catalogo},
+		Inscripciones: &EnrollmentService{store: store, catalogo: catalogo},
+	}
+}
+
+// verificarCurso comprueba en el catálogo que el curso exista. Distingue un
+// curso inexistente de un servicio de cursos caído.
+func verificarCurso(ctx context.Context, catalogo courses.Catalog, courseID string) error {
+	_, err := catalogo.GetCourse(ctx, courseID)
+	switch {
+	case errors.Is(err, courses.ErrCursoNoEncontrado):
+		return fmt.Errorf("curso con ID %s no encontrado", courseID)
+	case err != nil:
+		return fmt.Errorf("error al verificar el curso: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"ProyectoIngeso/courses"
+	"ProyectoIngeso/database/dbtest"
+	"ProyectoIngeso/migrations"
+	"ProyectoIngeso/models"
+	"ProyectoIngeso/repository"
+	"ProyectoIngeso/services"
+
+	"gorm.io/gorm"
+)
+
+// paraCadaStore corre prueba con servicios sobre FakeStore y sobre
+// GormStore en cada motor. El catálogo empieza con los cursos c1, c2 y c3.
+func paraCadaStore(t *testing.T, prueba func(t *testing.T, s services.Servicios, catalogo *courses.FakeCatalog)) {
+	correr := func(t *testing.T, store repository.Store) {
+		catalogo := courses.NewFakeCatalog(
+			courses.Course{ID: "c1", Price: 10},
+			courses.Course{ID: "c2", Price: 20},
+			courses.Course{ID: "c3", Price: 30},
+		)
+		prueba(t, services.New(store, catalogo), catalogo)
+	}
+
+	t.Run("fake", func(t *testing.T) {
+		correr(t, repository.NewFakeStore())
+	})
+	dbtest.ParaCadaMotor(t, func(t *testing.T, db *gorm.DB) {
+		if _, err := migrations.Subir(db); err != nil {
+			t.Fatal(err)
+		}
+		correr(t, repository.NewGormStore(db))
+	})
+}
+
+func registrar(t *testing.T, s services.Servicios, username string) *models.Usuario {
+	t.Helper()
+	usuario, err := s.Usuarios.Registrar(context.Background(), services.NuevoUsuario{
+		NombreCompleto: username,
+		Username:       username,
+		Email:          username + "@ejemplo.com",
+		Contrasena:     "secreta",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return usuario
+}
+
+// esperarError falla si err no contiene el texto indicado.
+func esperarError(t *testing.T, accion string, err error, texto string) {
+	t.Helper()
+	if err == nil || !strings.Contains(err.Error(), texto) {
+		t.Errorf("%s = %v, se esperaba un error con %q", accion, err, texto)
+	}
+}
+
+func TestRegistrarYAutenticar(t *testing.T) {
+	paraCadaStore(t, func(t *testing.T, s services.Servicios, _ *courses.FakeCatalog) {
+		ctx := context.Background()
+		ana := registrar(t, s, "ana")
+		if ana.Role != models.RolUsuario || ana.Password == "secreta" {
+			t.Errorf("usuario registrado = %+v", ana)
+		}
+
+		_, err := s.Usuarios.Registrar(ctx, services.NuevoUsuario{Username: "ana", Email: "otra@ejemplo.com", Contrasena: "x"})
+		esperarError(t, "Registrar con un username usado", err, "nombre de usuario ya está en uso")
+		_, err = s.Usuarios.Registrar(ctx, services.NuevoUsuario{Username: "otra", Email: "ana@ejemplo.com", Contrasena: "x"})
+		esperarError(t, "Registrar con un email usado", err, "email ya está en uso")
+
+		for _, identificador := range []string{"ana", "ana@ejemplo.com"} {
+			usuario, err := s.Usuarios.Autenticar(ctx, identificador, "secreta")
+			if err != nil || usuario.UserID != ana.UserID {
+				t.Errorf("Autenticar(%s) = %v, %v", identificador, usuario, err)
+			}
+		}
+		_, err = s.Usuarios.Autenticar(ctx, "ana", "incorrecta")
+		esperarError(t, "Autenticar con otra contraseña", err, "contraseña inválida")
+		if _, err := s.Usuarios.Autenticar(ctx, "nadie", "secreta"); !errors.Is(err, services.ErrUsuarioNoEncontrado) {
+			t.Errorf("Autenticar un usuario inexistente = %v", err)
+		}
+	})
+}
+
+func TestCambiarUsernameYEmail(t *testing.T) {
+	paraCadaStore(t, func(t *testing.T, s services.Servicios, _ *courses.FakeCatalog) {
+		ctx := context.Background()
+		ana := registrar(t, s, "ana")
+		registrar(t, s, "beto")
+
+		esperarError(t, "CambiarUsername al de otro usuario", s.Usuarios.CambiarUsername(ctx, ana, "beto"), "ya está en uso")
+		esperarError(t, "CambiarEmail al de otro usuario", s.Usuarios.CambiarEmail(ctx, ana, "beto@ejemplo.com"), "ya está en uso")
+		if ana.Username != "ana" || ana.Email != "ana@ejemplo.com" {
+			t.Errorf("un cambio rechazado modificó el usuario: %+v", ana)
+		}
+
+		// El valor actual del propio usuario no cuenta como usado
+		if err := s.Usuarios.CambiarEmail(ctx, ana, "ana@ejemplo.com"); err != nil {
+			t.Errorf("CambiarEmail al email actual = %v", err)
+		}
+
+		if err := s.Usuarios.CambiarUsername(ctx, ana, "anita"); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Usuarios.CambiarEmail(ctx, ana, "anita@ejemplo.com"); err != nil {
+			t.Fatal(err)
+		}
+		guardado, err := s.Usuarios.PorEmail(ctx, "anita@ejemplo.com")
+		if err != nil || guardado.UserID != ana.UserID || guardado.Username != "anita" {
+			t.Errorf("usuario tras los cambios = %+v, %v", guardado, err)
+		}
+		if _, err := s.Usuarios.PorUsername(ctx, "ana"); !errors.Is(err, services.ErrUsuarioNoEncontrado) {
+			t.Errorf("el username anterior sigue en uso: %v", err)
+		}
+	})
+}
+
+func TestCarrito(t *testing.T) {
+	paraCadaStore(t, func(t *testing.T, s services.Servicios, catalogo *courses.FakeCatalog) {
+		ctx := context.Background()
+		ana := registrar(t, s, "ana")
+		beto := registrar(t, s, "beto")
+
+		_, err := s.Carritos.Agregar(ctx, ana.UserID, "inexistente")
+		esperarError(t, "Agregar un curso inexistente", err, "no encontrado")
+		catalogo.SimularCaida(true)
+		if _, err := s.Carritos.Agregar(ctx, ana.UserID, "c1"); !errors.Is(err, courses.ErrServicioNoDisponible) {
+			t.Errorf("Agregar con el catálogo caído = %v", err)
+		}
+		catalogo.SimularCaida(false)
+
+		for _, courseID := range []string{"c1", "c2", "c3"} {
+			if _, err := s.Carritos.Agregar(ctx, ana.UserID, courseID); err != nil {
+				t.Fatal(err)
+			}
+		}
+		_, err = s.Carritos.Agregar(ctx, ana.UserID, "c1")
+		esperarError(t, "Agregar un curso que ya está en el carrito", err, "ya está en tu carrito")
+
+		if _, err := s.Inscripciones.Inscribir(ctx, beto.UserID, "c1", models.InscripcionAdmin); err != nil {
+			t.Fatal(err)
+		}
+		_, err = s.Carritos.Agregar(ctx, beto.UserID, "c1")
+		esperarError(t, "Agregar un curso inscrito", err, "ya tiene este curso")
+		if _, err := s.Carritos.Agregar(ctx, beto.UserID, "c2"); err != nil {
+			t.Fatal(err)
+		}
+
+		var vistos []string
+		despuesDe := ""
+		for {
+			pagina, err := s.Carritos.Pagina(ctx, ana.UserID, despuesDe, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pagina.Total != 3 {
+				t.Errorf("Total = %d, se esperaba 3", pagina.Total)
+			}
+			for _, item := range pagina.Items {
+				vistos = append(vistos, item.CourseID)
+			}
+			if !pagina.HaySiguiente {
+				break
+			}
+			despuesDe = pagina.Items[len(pagina.Items)-1].CartID
+		}
+		slices.Sort(vistos)
+		if !slices.Equal(vistos, []string{"c1", "c2", "c3"}) {
+			t.Errorf("cursos recorridos por página = %v", vistos)
+		}
+
+		afectados, err := s.Carritos.QuitarCursoDeTodos(ctx, "c2")
+		slices.Sort(afectados)
+		esperados := []string{ana.UserID, beto.UserID}
+		slices.Sort(esperados)
+		if err != nil || !slices.Equal(afectados, esperados) {
+			t.Errorf("QuitarCursoDeTodos = %v, %v", afectados, err)
+		}
+
+		if err := s.Carritos.Vaciar(ctx, ana.UserID); err != nil {
+			t.Fatal(err)
+		}
+		if items, err := s.Carritos.Items(ctx, ana.UserID); err != nil || len(items) != 0 {
+			t.Errorf("carrito vaciado = %+v, %v", items, err)
+		}
+	})
+}
+
+func TestInscribir(t *testing.T) {
+	paraCadaStore(t, func(t *testing.T, s services.Servicios, _ *courses.FakeCatalog) {
+		ctx := context.Background()
+		ana := registrar(t, s, "ana")
+
+		inscripcion, err := s.Inscripciones.Inscribir(ctx, ana.UserID, "c1", models.InscripcionRegalo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if inscripcion.Source != models.InscripcionRegalo || inscripcion.PaymentID != nil || inscripcion.EnrolledAt == "" {
+			t.Errorf("inscripción = %+v", inscripcion)
+		}
+
+		_, err = s.Inscripciones.Inscribir(ctx, ana.UserID, "c1", models.InscripcionAdmin)
+		esperarError(t, "Inscribir dos veces", err, "ya tiene este curso")
+		_, err = s.Inscripciones.Inscribir(ctx, ana.UserID, "inexistente", models.InscripcionAdmin)
+		esperarError(t, "Inscribir en un curso inexistente", err, "no encontrado")
+
+		inscripciones, err := s.Inscripciones.DeUsuario(ctx, ana.UserID)
+		if err != nil || len(inscripciones) != 1 || inscripciones[0].ID != inscripcion.ID {
+			t.Errorf("DeUsuario = %+v, %v", inscripciones, err)
+		}
+	})
+}
+
+func TestEliminarUsuario(t *testing.T) {
+	paraCadaStore(t, func(t *testing.T, s services.Servicios, _ *courses.FakeCatalog) {
+		ctx := context.Background()
+		ana := registrar(t, s, "ana")
+		if _, err := s.Carritos.Agregar(ctx, ana.UserID, "c1"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Inscripciones.Inscribir(ctx, ana.UserID, "c2", models.InscripcionAdmin); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := s.Usuarios.Eliminar(ctx, ana); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Usuarios.PorID(ctx, ana.UserID); !errors.Is(err, services.ErrUsuarioNoEncontrado) {
+			t.Errorf("PorID del usuario eliminado = %v", err)
+		}
+		if items, err := s.Carritos.Items(ctx, ana.UserID); err != nil || len(items) != 0 {
+			t.Errorf("carrito del usuario eliminado = %+v, %v", items, err)
+		}
+		if inscrito, err := s.Inscripciones.EstaInscrito(ctx, ana.UserID, "c2"); err != nil || inscrito {
+			t.Errorf("inscripción del usuario eliminado = %v, %v", inscrito, err)
+		}
+	})
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"ProyectoIngeso/models"
+	"ProyectoIngeso/repository"
+	"ProyectoIngeso/utils"
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrUsuarioNoEncontrado indica que el usuario buscado no existe.
+var ErrUsuarioNoEncontrado = errors.New("usuario no encontrado")
+
+// UserService reúne el registro, la autenticación y los cambios de datos de
+// los usuarios.
+type UserService struct {
+	store repository.Store
+}
+
+// NuevoUsuario son los datos de registro de un usuario.
+type NuevoUsuario struct {
+	NombreCompleto string
+	Username       string
+	Email          string
+	Contrasena     string
+}
+
+// Registrar crea un usuario con rol de usuario común. La verificación de
+// username y email libres y la creación van en la misma transacción, para
+// que dos registros simultáneos no choquen.
+func (s *UserService) Registrar(ctx context.Context, datos NuevoUsuario) (*models.Usuario, error) {
+	hash, err := utils.HashContrasena(datos.Contrasena)
+	if err != nil {
+		return nil, errors.New("error al cifrar la contraseña")
+	}
+
+	usuario := models.Usuario{
+		UserID:       uuid.NewString(),
+		NameLastName: datos.NombreCompleto,
+		Username:     datos.Username,
+		Email:        datos.Email,
+		Password:     hash,
+		Role:         models.RolUsuario,
+	}
+
+	err = s.store.EnTransaccion(ctx, func(repos repository.Repositorios) error {
+		if err := verificarUsernameLibre(ctx, repos.Usuarios, usuario.Username, ""); err != nil {
+			return err
+		}
+		if err := verificarEmailLibre(ctx, repos.Usuarios, usuario.Email, ""); err != nil {
+			return err
+		}
+		if err := repos.Usuarios.Crear(ctx, &usuario); err != nil {
+			return errors.New("error al crear el usuario")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &usuario, nil
+}
+
+// Autenticar busca al usuario por email o username y verifica su contraseña.
+func (s *UserService) Autenticar(ctx context.Context, identificador string, contrasena string) (*models.Usuario, error) {
+	usuario, err := s.store.Repositorios().Usuarios.PorIdentificador(ctx, identificador)
+	if err != nil {
+		return nil, errorUsuario(err)
+	}
+	if !utils.VerificarHashContrasena(contrasena, usuario.Password) {
+		return nil, errors.New("contraseña inválida")
+	}
+	return usuario, nil
+}
+
+// PorID, PorEmail y PorUsername devuelven ErrUsuarioNoEncontrado si el
+// usuario no existe.
+func (s *UserService) PorID(ctx context.Context, userID string) (*models.Usuario, error) {
+	usuario, err := s.store.Repositorios().Usuarios.PorID(ctx, userID)
+	return usuario, errorUsuario(err)
+}
+
+func (s *UserService) PorEmail(ctx context.Context, email string) (*models.Usuario, error) {
+	usuario, err := s.store.Repositorios().Usuarios.PorEmail(ctx, email)
+	return usuario, errorUsuario(err)
+}
+
+func (s *UserService) PorUsername(ctx context.Context, username string) (*models.Usuario, error) {
+	usuario, err := s.store.Repositorios().Usuarios.PorUsername(ctx, username)
+	return usuario, errorUsuario(err)
+}
+
+// Todos devuelve todos los usuarios.
+func (s *UserService) Todos(ctx context.Context) ([]models.Usuario, error) {
+	return s.store.Repositorios().Usuarios.Todos(ctx)
+}
+
+// CambiarUsername asigna un nuevo username al usuario si ningún otro lo usa.
+func (s *UserService) CambiarUsername(ctx context.Context, usuario *models.Usuario, nuevo string) error {
+	return s.store.EnTransaccion(ctx, func(repos repository.Repositorios) error {
+		if err := verificarUsernameLibre(ctx, repos.Usuarios, nuevo, usuario.UserID); err != nil {
+			return err
+		}
+		if err := repos.Usuarios.Actualizar(ctx, usuario, repository.CambiosUsuario{Username: &nuevo}); err != nil {
+			return errors.New("no se pudo actualizar el nombre de usuario")
+		}
+		return nil
+	})
+}
+
+// CambiarEmail asigna un nuevo email al usuario si ningún otro lo usa.
+func (s *UserService) CambiarEmail(ctx context.Context, usuario *models.Usuario, nuevo string) error {
+	return s.store.EnTransaccion(ctx, func(repos repository.Repositorios) error {
+		if err := verificarEmailLibre(ctx, repos.Usuarios, nuevo, usuario.UserID); err != nil {
+			return err
+		}
+		if err := repos.Usuarios.Actualizar(ctx, usuario, repository.CambiosUsuario{Email: &nuevo}); err != nil {
+			return errors.New("no se pudo actualizar el email")
+		}
+		return nil
+	})
+}
+
+// CambiarNombre actualiza el nombre completo del usuario.
+func (s *UserService) CambiarNombre(ctx context.Context, usuario *models.Usuario, nuevo string) error {
+	if err := s.store.Repositorios().Usuarios.Actualizar(ctx, usuario, repository.CambiosUsuario{NameLastName: &nuevo}); err != nil {
+		return errors.New("no se pudo actualizar el nombre completo")
+	}
+	return nil
+}
+
+// CambiarContrasena reemplaza la contraseña del usuario si actual es correcta.
+func (s *UserService) CambiarContrasena(ctx context.Context, usuario *models.Usuario, actual string, nueva string) error {
+	if !utils.VerificarHashContrasena(actual, usuario.Password) {
+		return errors.New("la contraseña actual es incorrecta")
+	}
+
+	hash, err := utils.HashContrasena(nueva)
+	if err != nil {
+		return errors.New("error al cifrar la nueva contraseña")
+	}
+
+	// Se escribe solo la contraseña, sin pisar otros cambios del usuario
+	if err := s.store.Repositorios().Usuarios.Actualizar(ctx, usuario, repository.CambiosUsuario{Password: &hash}); err != nil {
+		return errors.New("no se pudo actualizar la contraseña")
+	}
+	return nil
+}
+
+// CambiarRol asigna el rol al usuario. El rol ya debe estar validado.
+func (s *UserService) CambiarRol(ctx context.Context, usuario *models.Usuario, rol string) error {
+	if err := s.store.Repositorios().Usuarios.Actualizar(ctx, usuario, repository.CambiosUsuario{Role: &rol}); err != nil {
+		return errors.New("no se pudo actualizar el rol")
+	}
+	return nil
+}
+
+// Eliminar borra el usuario; su carrito e inscripciones se van con él.
+func (s *UserService) Eliminar(ctx context.Context, usuario *models.Usuario) error {
+	if err := s.store.Repositorios().Usuarios.Eliminar(ctx, usuario.UserID); err != nil {
+		return errors.New("no se pudo eliminar el usuario")
+	}
+	return nil
+}
+
+// errorUsuario traduce la ausencia del registro a ErrUsuarioNoEncontrado.
+func errorUsuario(err error) error {
+	if errors.Is(err, repository.ErrNoEncontrado) {
+		return ErrUsuarioNoEncontrado
+	}
+	return err
+}
+
+func verificarUsernameLibre(ctx context.Context, usuarios repository.UserRepository, username string, excluirID string) error {
+	usado, err := usuarios.UsernameEnUso(ctx, username, excluirID)
+	if err != nil {
+		return err
+	}
+	if usado {
+		return errors.New("el nombre de usuario ya está en uso")
+	}
+	return nil
+}
+
+func verificarEmailLibre(ctx context.Context, usuarios repository.UserRepository, email string, excluirID string) error {
+	usado, err := usuarios.EmailEnUso(ctx, email, excluirID)
+	if err != nil {
+		return err
+	}
+	if usado {
+		return errors.New("el email ya está en uso")
+	}
+	return nil
+}
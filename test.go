@@ -1,76 +1,315 @@
 package main
 
 import (
+	"ProyectoIngeso/config"
+	"ProyectoIngeso/courses"
+	"ProyectoIngeso/database"
+	"ProyectoIngeso/events"
 	"ProyectoIngeso/graph"
 	"ProyectoIngeso/graph/model"
+	"ProyectoIngeso/migrations"
 	"ProyectoIngeso/models"
-	"ProyectoIngeso/mq"
+	mq "ProyectoIngeso/mq"
+	"ProyectoIngeso/payments"
+	"ProyectoIngeso/pubsub"
+	"ProyectoIngeso/repository"
+	"ProyectoIngeso/services"
+	"ProyectoIngeso/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/gorilla/websocket"
 	"github.com/rs/cors" // Importar el middleware CORS
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
+	"github.com/vektah/gqlparser/v2/ast"
+	"io"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
 )
 
-var bd *gorm.DB
-
-func init() {
-	var err error
-	bd, err = gorm.Open(sqlite.Open("base_datos.db"), &gorm.Config{})
+func main() {
+	cfg, err := config.Cargar()
 	if err != nil {
-		panic("No se pudo conectar a la base de datos")
+		log.Fatalf("Error al cargar la configuración: %s", err)
 	}
+	utils.ConfigurarJWT(cfg.JWT.Secreto, cfg.JWT.DuracionAcceso, cfg.JWT.DuracionRefresh)
 
-	// Migrar el esquema de Curso y Usuario
-	err = bd.AutoMigrate(&models.Carrito{})
+	bd, err := database.Abrir(cfg.BD)
 	if err != nil {
-		log.Fatal("Error al migrar la base de datos", err)
+		log.Fatal(err)
 	}
 
-	// Migración automática del modelo Usuario
-	err = bd.AutoMigrate(
-		&models.Usuario{},
-		&models.Reseña{},
-		&model.Carrito{},
-		&models.UsuarioCurso{},
-		&models.Carrito{},
-		&models.Pago{},
-		&models.Notificación{},
-	)
-	if err != nil {
+	// Subcomando de migraciones: "main migrate up|down|status"
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := ejecutarMigrate(bd, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
 		return
 	}
-}
 
-func main() {
-	// Iniciar consumidor de RabbitMQ
-	go func() {
-		err := utils.StartUserConsumer() // Asegúrate de que la función StartUserConsumer sea pública
-		if err != nil {
-			log.Fatalf("Error al iniciar el consumidor de RabbitMQ: %s", err)
+	// Limpieza única de datos duplicados y huérfanos: "main repair"
+	if len(os.Args) > 1 && os.Args[1] == "repair" {
+		if err := ejecutarRepair(bd); err != nil {
+			log.Fatal(err)
 		}
-	}()
+		return
+	}
+
+	// No atender peticiones con un esquema distinto al que espera el código
+	if err := migrations.VerificarAlDia(bd); err != nil {
+		log.Fatalf("%s; ejecuta \"migrate up\" antes de iniciar el servidor", err)
+	}
+
+	// Pasarela de pagos según payments.provider
+	pasarela, err := payments.New(cfg.Pagos.Proveedor, cfg.Pagos.SecretoWebhook)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Cliente del servicio de cursos
+	catalogoRemoto := courses.NewHTTPCatalog(cfg.Cursos.URL, nil)
+	catalogo := courses.NewCachedCatalog(catalogoRemoto,
+		cfg.Cursos.TamanoCache, cfg.Cursos.TTLCache, cfg.Cursos.TTLNegativo)
+
+	// Servicios compartidos por GraphQL y RabbitMQ
+	store := repository.NewGormStore(bd)
+	servicios := services.New(store, catalogo)
+
+	// Conexión con RabbitMQ: se reintenta en segundo plano mientras el broker
+	// no esté disponible, sin detener el servidor
+	rabbit := utils.NuevoGestorRabbitMQ(cfg.RabbitMQ.URL)
+
 	// Resolver
-	resolver := graph.Resolver{DB: bd}
+	resolver := graph.Resolver{
+		Store:          store,
+		Pagos:          pasarela,
+		Cursos:         catalogo,
+		Precios:        catalogoRemoto,
+		Servicios:      servicios,
+		Eventos:        mq.NuevoPublicadorEventos(rabbit),
+		Notificaciones: pubsub.NewBroker[*model.Notificacion](),
+		Carritos:       pubsub.NewBroker[*model.CartUpdate](),
+	}
+
+	// Solicitudes RPC de otros servicios por users_queue
+	registroRPC := mq.NuevoRegistroRPC(cfg.RabbitMQ.EsperaManejador)
+	mq.RegistrarPatronesUsuarios(registroRPC, servicios, resolver.NotificarCarritoVaciado)
+	consumidor := mq.StartUserConsumer(rabbit, registroRPC, mq.OpcionesTrabajadores{
+		Trabajadores: cfg.RabbitMQ.Trabajadores,
+		Prefetch:     cfg.RabbitMQ.Prefetch,
+	})
+
+	// Invalidar el caché de cursos cuando el servicio de cursos los modifica
+	mq.StartCourseEventsConsumer(rabbit, catalogo.Invalidar)
+
+	ctxRabbit, detenerRabbit := context.WithCancel(context.Background())
+	rabbitDetenido := make(chan struct{})
+	go func() {
+		rabbit.Ejecutar(ctxRabbit)
+		close(rabbitDetenido)
+	}()
 
 	// Servidor GraphQL
-	srv := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{Resolvers: &resolver}))
+	srv := nuevoServidorGraphQL(servicios.Usuarios, catalogo, cfg.Servidor.Origenes, graph.NewExecutableSchema(graph.Config{
+		Resolvers:  &resolver,
+		Directives: graph.DirectiveRoot{HasRole: graph.HasRole},
+	}))
 
 	// Middleware CORS
 	corsHandler := cors.New(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:3000"}, // Cambia esto si tu frontend está en otro dominio o puerto
+		AllowedOrigins:   cfg.Servidor.Origenes,
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
 		AllowCredentials: true,
-	}).Handler(srv)
+	}).Handler(authMiddleware(servicios.Usuarios, srv))
 
 	http.Handle("/graphql", corsHandler)
+	http.Handle("/webhooks/payments", webhookPagosHandler(&resolver))
 	http.Handle("/playground", playground.Handler("GraphQL Playground", "/graphql"))
+	http.Handle("/ready", readyHandler(rabbit))
+	http.Handle("/metrics/rpc", metricasRPCHandler(registroRPC))
+	http.Handle("/events/schemas/", http.StripPrefix("/events/", http.FileServer(http.FS(events.Schemas))))
 
-	log.Println("Iniciando servidor en :8080...")
+	log.Printf("Iniciando servidor en %s...", cfg.Servidor.Direccion())
 
-	err := http.ListenAndServe(":8080", nil)
-	if err != nil {
+	servidor := &http.Server{Addr: cfg.Servidor.Direccion()}
+	errServidor := make(chan error, 1)
+	go func() {
+		errServidor <- servidor.ListenAndServe()
+	}()
+
+	senales, dejarDeEscuchar := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer dejarDeEscuchar()
+
+	select {
+	case err := <-errServidor:
 		log.Fatalf("No se pudo iniciar el servidor: %s\n", err)
+	case <-senales.Done():
+	}
+
+	// Apagado ordenado: primero dejar de aceptar peticiones HTTP, después
+	// terminar los mensajes en proceso y por último cerrar la conexión
+	log.Printf("Apagando servidor...")
+	ctxApagado, cancelar := context.WithTimeout(context.Background(), cfg.Servidor.EsperaApagado)
+	defer cancelar()
+
+	if err := servidor.Shutdown(ctxApagado); err != nil {
+		log.Printf("Error al apagar el servidor HTTP: %s", err)
 	}
+	if err := consumidor.Drenar(ctxApagado); err != nil {
+		log.Printf("Error al drenar el consumidor de RabbitMQ: %s", err)
+	}
+	detenerRabbit()
+	<-rabbitDetenido
+}
+
+// authMiddleware valida el encabezado "Authorization: Bearer <token>" y guarda
+// el usuario autenticado en el contexto. Las peticiones sin encabezado pasan
+// como anónimas; un token inválido se rechaza con 401.
+func authMiddleware(usuarios *services.UserService, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		usuario, err := autenticar(r.Context(), usuarios, header)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		ctx := utils.ContextoConUsuario(r.Context(), usuario)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// autenticar valida un valor "Bearer <token>" y carga el usuario del token.
+func autenticar(ctx context.Context, usuarios *services.UserService, header string) (*models.Usuario, error) {
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok {
+		return nil, errors.New("encabezado Authorization inválido")
+	}
+
+	claims, err := utils.ValidarToken(token, utils.TokenAcceso)
+	if err != nil {
+		return nil, err
+	}
+
+	return usuarios.PorID(ctx, claims.UserID)
+}
+
+// nuevoServidorGraphQL arma el servidor con los mismos transportes que
+// handler.NewDefaultServer, pero con un websocket que autentica en
+// connection_init (el navegador no puede enviar encabezados al abrirlo). Cada
+// operación recibe su propio cargador de cursos. origenes son los orígenes
+// del frontend aceptados al abrir el websocket.
+func nuevoServidorGraphQL(usuarios *services.UserService, catalogo courses.Catalog, origenes []string, schema graphql.ExecutableSchema) *handler.Server {
+	srv := handler.New(schema)
+
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+		Upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				origen := r.Header.Get("Origin")
+				return origen == "" || slices.Contains(origenes, origen)
+			},
+		},
+		InitFunc: func(ctx context.Context, payload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+			header := payload.Authorization()
+			if header == "" {
+				return ctx, &payload, nil
+			}
+			usuario, err := autenticar(ctx, usuarios, header)
+			if err != nil {
+				return ctx, nil, err
+			}
+			return utils.ContextoConUsuario(ctx, usuario), &payload, nil
+		},
+	})
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{})
+
+	srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
+
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.AutomaticPersistedQuery{
+		Cache: lru.New[string](100),
+	})
+
+	srv.AroundOperations(func(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+		return next(graph.ContextoConCargadorCursos(ctx, catalogo))
+	})
+
+	return srv
+}
+
+// webhookPagosHandler recibe las notificaciones de la pasarela de pagos. La
+// firma viaja en el encabezado X-Signature.
+func webhookPagosHandler(resolver *graph.Resolver) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "método no permitido", http.StatusMethodNotAllowed)
+			return
+		}
+
+		payload, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, "no se pudo leer el cuerpo", http.StatusBadRequest)
+			return
+		}
+
+		evento, err := resolver.Pagos.VerifyWebhook(payload, r.Header.Get("X-Signature"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		if err := resolver.ProcesarEventoPago(r.Context(), evento); err != nil {
+			log.Printf("Error al procesar el webhook de pagos: %s", err)
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// readyHandler informa si el servidor puede atender todo: responde 503
+// mientras no haya conexión con RabbitMQ.
+func readyHandler(rabbit *utils.GestorRabbitMQ) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		listo := rabbit.Listo()
+		estado := map[string]string{"rabbitmq": "up"}
+		if !listo {
+			estado["rabbitmq"] = "down"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if !listo {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(estado)
+	})
+}
+
+// metricasRPCHandler expone las métricas de cada patrón RPC de users_queue.
+func metricasRPCHandler(registro *mq.RegistroRPC) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(registro.Metricas())
+	})
 }
@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"context"
+
+	"ProyectoIngeso/models"
+)
+
+type usuarioCtxKey struct{}
+
+// ContextoConUsuario guarda el usuario autenticado en el contexto de la petición.
+func ContextoConUsuario(ctx context.Context, usuario *models.Usuario) context.Context {
+	return context.WithValue(ctx, usuarioCtxKey{}, usuario)
+}
+
+// UsuarioDesdeContexto obtiene el usuario autenticado, si existe.
+func UsuarioDesdeContexto(ctx context.Context) (*models.Usuario, bool) {
+	usuario, ok := ctx.Value(usuarioCtxKey{}).(*models.Usuario)
+	return usuario, ok && usuario != nil
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"ProyectoIngeso/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Tipos de token emitidos por el servicio.
+const (
+	TokenAcceso    = "access"
+	TokenRefresco  = "refresh"
+	issuerUsuarios = "proyectoingeso-usuarios"
+)
+
+var (
+	jwtSecret       = []byte("cambiar-en-produccion") // Reemplazado por ConfigurarJWT al iniciar
+	duracionAcceso  = 15 * time.Minute
+	duracionRefresh = 7 * 24 * time.Hour
+)
+
+// Claims es el contenido firmado dentro de cada token.
+type Claims struct {
+	UserID string `json:"userID"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+	Tipo   string `json:"tipo"`
+	jwt.RegisteredClaims
+}
+
+// ConfigurarJWT reemplaza el secreto y las duraciones usadas para firmar tokens.
+func ConfigurarJWT(secreto string, acceso, refresh time.Duration) {
+	if secreto != "" {
+		jwtSecret = []byte(secreto)
+	}
+	if acceso > 0 {
+		duracionAcceso = acceso
+	}
+	if refresh > 0 {
+		duracionRefresh = refresh
+	}
+}
+
+// GenerarTokens firma un token de acceso y uno de refresco para el usuario.
+// Devuelve también el instante en que expira el token de acceso.
+func GenerarTokens(usuario models.Usuario) (string, string, time.Time, error) {
+	ahora := time.Now()
+	expiraAcceso := ahora.Add(duracionAcceso)
+
+	acceso, err := firmarToken(usuario, TokenAcceso, ahora, expiraAcceso)
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+
+	refresh, err := firmarToken(usuario, TokenRefresco, ahora, ahora.Add(duracionRefresh))
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+
+	return acceso, refresh, expiraAcceso, nil
+}
+
+// ValidarToken verifica la firma, la expiración y el tipo del token.
+func ValidarToken(token string, tipo string) (*Claims, error) {
+	claims := &Claims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return jwtSecret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(issuerUsuarios),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || !parsed.Valid {
+		return nil, errors.New("token inválido o expirado")
+	}
+	if claims.Tipo != tipo {
+		return nil, errors.New("tipo de token inválido")
+	}
+	return claims, nil
+}
+
+func firmarToken(usuario models.Usuario, tipo string, emitido, expira time.Time) (string, error) {
+	claims := Claims{
+		UserID: usuario.UserID,
+		Email:  usuario.Email,
+		Role:   usuario.Role,
+		Tipo:   tipo,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuerUsuarios,
+			Subject:   usuario.UserID,
+			IssuedAt:  jwt.NewNumericDate(emitido),
+			ExpiresAt: jwt.NewNumericDate(expira),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret)
+}